@@ -16,6 +16,25 @@ type Scan struct {
 	RelativePath string
 	Priority     int
 	Time         int64 // Unix timestamp
+
+	// RelativePaths holds every relative path queued for the Folder.
+	// It is set by the Processor, which collects the RelativePath of each
+	// Scan of the Folder until the Folder is scanned.
+	RelativePaths []string
+}
+
+// Paths returns the paths relative to the Folder which should be scanned.
+// An empty result means that the Folder as a whole should be scanned.
+func (s Scan) Paths() []string {
+	if len(s.RelativePaths) > 0 {
+		return s.RelativePaths
+	}
+
+	if s.RelativePath != "" {
+		return []string{s.RelativePath}
+	}
+
+	return nil
 }
 
 // ProcessorFunc is a callback that receives one or more media scans for processing.
@@ -33,12 +52,34 @@ type HTTPTrigger func(ProcessorFunc) http.Handler
 // A Target receives a Scan from the Processor and translates the Scan
 // into a format understood by the target.
 //
+// ID must return a stable identifier which is unique across all configured
+// targets. The processor uses it to track which targets have received a Scan.
+//
 //nolint:iface // Target is the core public contract; all target packages implement it externally
 type Target interface {
+	ID() string
 	Scan(Scan) error
 	Available() error
 }
 
+// TargetLimits restrict the load the Processor puts on a single Target.
+// A zero value disables the corresponding limit.
+type TargetLimits struct {
+	// MaxInFlight is the maximum amount of scans sent to the Target concurrently.
+	MaxInFlight int `yaml:"max-in-flight"`
+	// RateLimit is the maximum amount of scans sent to the Target per second.
+	RateLimit float64 `yaml:"rate-limit"`
+}
+
+// A Coalescer is a Target which may scan a folder instead of its subfolders,
+// as a scan of the folder also scans all of its subfolders.
+//
+// When Coalesce returns true, the processor delivers a single scan of a
+// queued folder to the target in place of the queued scans of its subfolders.
+type Coalescer interface {
+	Coalesce() bool
+}
+
 const maxResponseBodySize = 10 * 1024 * 1024 // 10MB
 
 // limitedReadCloser wraps an io.LimitedReader with the original closer.
@@ -65,9 +106,14 @@ var (
 
 	// ErrTargetUnavailable may occur when a Target goes offline
 	// or suffers from fatal errors. In this case, the processor
-	// will halt operations until the target is back online.
+	// holds back scans for the target until it is back online.
 	ErrTargetUnavailable = errors.New("target unavailable")
 
+	// ErrTargetBusy may occur when a Target is online, but cannot
+	// accept a scan right now. The processor holds back the scan
+	// for the target without considering it unavailable.
+	ErrTargetBusy = errors.New("target busy")
+
 	// ErrFatal indicates a severe problem related to development.
 	ErrFatal = errors.New("fatal error")
 
@@ -3,6 +3,7 @@ package autoscan
 import (
 	"bytes"
 	"io"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -27,6 +28,42 @@ func TestLimitReadCloserTruncates(t *testing.T) {
 	}
 }
 
+func TestScanPaths(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Scan     Scan
+		Expected []string
+	}{
+		{
+			Name:     "Folder",
+			Scan:     Scan{Folder: "/tv/Westworld"},
+			Expected: nil,
+		},
+		{
+			Name:     "Relative path",
+			Scan:     Scan{Folder: "/tv/Westworld", RelativePath: "s01e01.mkv"},
+			Expected: []string{"s01e01.mkv"},
+		},
+		{
+			Name: "Relative paths",
+			Scan: Scan{
+				Folder:        "/tv/Westworld",
+				RelativePath:  "s01e02.mkv",
+				RelativePaths: []string{"s01e01.mkv", "s01e02.mkv"},
+			},
+			Expected: []string{"s01e01.mkv", "s01e02.mkv"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := tc.Scan.Paths(); !reflect.DeepEqual(got, tc.Expected) {
+				t.Errorf("Paths do not match: %v vs %v", got, tc.Expected)
+			}
+		})
+	}
+}
+
 func TestRewriter(t *testing.T) {
 	type Test struct {
 		Name     string
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/hlog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/processor"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// errInvalidQuery is returned by scan actions when a query parameter is malformed.
+var errInvalidQuery = errors.New("invalid query")
+
+type scanResponse struct {
+	Folder        string   `json:"folder"`
+	RelativePath  string   `json:"relative_path"`
+	RelativePaths []string `json:"relative_paths"`
+	Priority      int      `json:"priority"`
+	Time          int64    `json:"time"`
+	Attempts      int      `json:"attempts"`
+	LastError     string   `json:"last_error"`
+	NextAttempt   int64    `json:"next_attempt"`
+	Forced        bool     `json:"forced"`
+}
+
+type scanListResponse struct {
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+	Scans  []scanResponse `json:"scans"`
+}
+
+// relativePaths returns the relative paths of the scan, which are empty
+// when the folder as a whole is scanned.
+func relativePaths(scan autoscan.Scan) []string {
+	if paths := scan.Paths(); paths != nil {
+		return paths
+	}
+	return []string{}
+}
+
+func newScanResponse(scan processor.QueuedScan) scanResponse {
+	return scanResponse{
+		Folder:        scan.Folder,
+		RelativePath:  scan.RelativePath,
+		RelativePaths: relativePaths(scan.Scan),
+		Priority:      scan.Priority,
+		Time:          scan.Time,
+		Attempts:      scan.Attempts,
+		LastError:     scan.LastError,
+		NextAttempt:   scan.NextAttempt,
+		Forced:        scan.Forced,
+	}
+}
+
+type failedScanResponse struct {
+	Folder        string   `json:"folder"`
+	RelativePath  string   `json:"relative_path"`
+	RelativePaths []string `json:"relative_paths"`
+	Priority      int      `json:"priority"`
+	Time          int64    `json:"time"`
+	Attempts      int      `json:"attempts"`
+	LastError     string   `json:"last_error"`
+	FailedAt      int64    `json:"failed_at"`
+}
+
+// writeJSON encodes v as the JSON response body with the given status code.
+func writeJSON(rw http.ResponseWriter, r *http.Request, status int, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		hlog.FromRequest(r).Error().Err(err).Msg("Response Encode Failed")
+	}
+}
+
+// failedScansHandler lists all scans in the dead-letter table.
+func failedScansHandler(proc *processor.Processor) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		rlog := hlog.FromRequest(r)
+
+		scans, err := proc.FailedScans()
+		if err != nil {
+			rlog.Error().Err(err).Msg("Failed Scans Retrieval Failed")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]failedScanResponse, 0, len(scans))
+		for _, scan := range scans {
+			resp = append(resp, failedScanResponse{
+				Folder:        scan.Folder,
+				RelativePath:  scan.RelativePath,
+				RelativePaths: relativePaths(scan.Scan),
+				Priority:      scan.Priority,
+				Time:          scan.Time,
+				Attempts:      scan.Attempts,
+				LastError:     scan.LastError,
+				FailedAt:      scan.FailedAt,
+			})
+		}
+
+		writeJSON(rw, r, http.StatusOK, resp)
+	}
+}
+
+// requeueHandler moves a failed scan, given by the folder query parameter, back into the queue.
+func requeueHandler(proc *processor.Processor) http.HandlerFunc {
+	return scanActionHandler("Scan Requeued", func(_ *http.Request, folder string) error {
+		return proc.Requeue(folder)
+	})
+}
+
+// queryInt parses the named query parameter as an integer, returning def when it is absent.
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", name, err)
+	}
+
+	return i, nil
+}
+
+// listOptions parses the paging and filter query parameters of the scan list.
+func listOptions(r *http.Request) (processor.ListOptions, error) {
+	opts := processor.ListOptions{
+		Prefix: r.URL.Query().Get("prefix"),
+	}
+
+	var err error
+	if opts.MinPriority, err = queryInt(r, "min-priority", math.MinInt); err != nil {
+		return opts, err
+	}
+
+	if opts.Limit, err = queryInt(r, "limit", defaultPageLimit); err != nil {
+		return opts, err
+	}
+
+	if opts.Offset, err = queryInt(r, "offset", 0); err != nil {
+		return opts, err
+	}
+
+	if opts.Limit < 1 || opts.Limit > maxPageLimit || opts.Offset < 0 {
+		return opts, fmt.Errorf("limit must be between 1 and %d, offset must not be negative", maxPageLimit)
+	}
+
+	return opts, nil
+}
+
+// scansHandler lists the queued scans, filtered by the prefix and min-priority
+// query parameters and paged by the limit and offset query parameters.
+func scansHandler(proc *processor.Processor) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		rlog := hlog.FromRequest(r)
+
+		opts, err := listOptions(r)
+		if err != nil {
+			rlog.Error().Err(err).Msg("Invalid Query")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		scans, total, err := proc.QueuedScans(opts)
+		if err != nil {
+			rlog.Error().Err(err).Msg("Scans Retrieval Failed")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		resp := scanListResponse{
+			Total:  total,
+			Limit:  opts.Limit,
+			Offset: opts.Offset,
+			Scans:  make([]scanResponse, 0, len(scans)),
+		}
+
+		for _, scan := range scans {
+			resp.Scans = append(resp.Scans, newScanResponse(scan))
+		}
+
+		writeJSON(rw, r, http.StatusOK, resp)
+	}
+}
+
+// scanHandler returns the queued scan given by the folder query parameter.
+func scanHandler(proc *processor.Processor) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		rlog := hlog.FromRequest(r)
+
+		folder := r.URL.Query().Get("folder")
+		if folder == "" {
+			rlog.Error().Msg("Required Fields Missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		scan, err := proc.QueuedScan(folder)
+		switch {
+		case errors.Is(err, processor.ErrScanNotFound):
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		case err != nil:
+			rlog.Error().Err(err).Str("folder", folder).Msg("Scan Retrieval Failed")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(rw, r, http.StatusOK, newScanResponse(scan))
+	}
+}
+
+// scanActionHandler applies action to the queued scan given by the folder query parameter.
+func scanActionHandler(msg string, action func(r *http.Request, folder string) error) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		rlog := hlog.FromRequest(r)
+
+		folder := r.URL.Query().Get("folder")
+		if folder == "" {
+			rlog.Error().Msg("Required Fields Missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err := action(r, folder)
+		switch {
+		case errors.Is(err, processor.ErrScanNotFound):
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		case errors.Is(err, processor.ErrScanProcessing):
+			rlog.Warn().Str("folder", folder).Msg("Scan Being Processed")
+			rw.WriteHeader(http.StatusConflict)
+			return
+		case errors.Is(err, errInvalidQuery):
+			rlog.Error().Err(err).Msg("Invalid Query")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		case err != nil:
+			rlog.Error().Err(err).Str("folder", folder).Msg("Scan Update Failed")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		rlog.Info().Str("folder", folder).Msg(msg)
+		rw.WriteHeader(http.StatusOK)
+	}
+}
+
+// deleteScanHandler removes the queued scan given by the folder query parameter.
+func deleteScanHandler(proc *processor.Processor) http.HandlerFunc {
+	return scanActionHandler("Scan Removed", func(_ *http.Request, folder string) error {
+		return proc.Remove(folder)
+	})
+}
+
+// priorityHandler changes the priority of the queued scan given by the folder
+// query parameter to the value of the priority query parameter.
+func priorityHandler(proc *processor.Processor) http.HandlerFunc {
+	return scanActionHandler("Scan Priority Changed", func(r *http.Request, folder string) error {
+		if r.URL.Query().Get("priority") == "" {
+			return fmt.Errorf("priority missing: %w", errInvalidQuery)
+		}
+
+		priority, err := queryInt(r, "priority", 0)
+		if err != nil {
+			return fmt.Errorf("%w: %w", err, errInvalidQuery)
+		}
+
+		return proc.SetPriority(folder, priority)
+	})
+}
+
+// forceHandler moves the queued scan given by the folder query parameter to the front of the queue.
+func forceHandler(proc *processor.Processor) http.HandlerFunc {
+	return scanActionHandler("Scan Forced", func(_ *http.Request, folder string) error {
+		return proc.Force(folder)
+	})
+}
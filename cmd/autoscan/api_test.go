@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/sqlite"
+	"github.com/cloudbox/autoscan/processor"
+	"github.com/cloudbox/autoscan/stats"
+)
+
+// blockingTarget holds every scan until it is released.
+type blockingTarget struct {
+	scanning chan struct{}
+	release  chan struct{}
+}
+
+func (blockingTarget) ID() string {
+	return "blocking"
+}
+
+func (t blockingTarget) Scan(autoscan.Scan) error {
+	t.scanning <- struct{}{}
+	<-t.release
+	return nil
+}
+
+func (blockingTarget) Available() error {
+	return nil
+}
+
+// newTestProcessor returns a processor with the given scans queued.
+func newTestProcessor(t *testing.T, scans ...autoscan.Scan) *processor.Processor {
+	t.Helper()
+
+	db, err := sqlite.NewDB(context.Background(), filepath.Join(t.TempDir(), "autoscan.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proc, err := processor.New(processor.Config{
+		Db:          db,
+		Stats:       stats.New(),
+		MaxAttempts: 5,
+		RetryDelay:  time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = proc.Close()
+	})
+
+	if err := proc.Add(scans...); err != nil {
+		t.Fatal(err)
+	}
+
+	return proc
+}
+
+func TestScanHandlers(t *testing.T) {
+	type Test struct {
+		Name       string
+		Handler    func(*processor.Processor) http.HandlerFunc
+		Method     string
+		URL        string
+		StatusCode int
+		Scan       *processor.QueuedScan
+	}
+
+	testCases := []Test{
+		{
+			Name:       "Get scan",
+			Handler:    scanHandler,
+			Method:     http.MethodGet,
+			URL:        "/api/scans/scan?folder=/movies/Tenet",
+			StatusCode: http.StatusOK,
+			Scan:       &processor.QueuedScan{Scan: autoscan.Scan{Folder: "/movies/Tenet", Priority: 1, Time: 1600000000}},
+		},
+		{
+			Name:       "Get unknown scan",
+			Handler:    scanHandler,
+			Method:     http.MethodGet,
+			URL:        "/api/scans/scan?folder=/movies/Dune",
+			StatusCode: http.StatusNotFound,
+		},
+		{
+			Name:       "Get scan without folder",
+			Handler:    scanHandler,
+			Method:     http.MethodGet,
+			URL:        "/api/scans/scan",
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:       "Remove scan",
+			Handler:    deleteScanHandler,
+			Method:     http.MethodDelete,
+			URL:        "/api/scans/scan?folder=/movies/Tenet",
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name:       "Remove unknown scan",
+			Handler:    deleteScanHandler,
+			Method:     http.MethodDelete,
+			URL:        "/api/scans/scan?folder=/movies/Dune",
+			StatusCode: http.StatusNotFound,
+			Scan:       &processor.QueuedScan{Scan: autoscan.Scan{Folder: "/movies/Tenet", Priority: 1, Time: 1600000000}},
+		},
+		{
+			Name:       "Change priority",
+			Handler:    priorityHandler,
+			Method:     http.MethodPut,
+			URL:        "/api/scans/priority?folder=/movies/Tenet&priority=5",
+			StatusCode: http.StatusOK,
+			Scan:       &processor.QueuedScan{Scan: autoscan.Scan{Folder: "/movies/Tenet", Priority: 5, Time: 1600000000}},
+		},
+		{
+			Name:       "Change priority without priority",
+			Handler:    priorityHandler,
+			Method:     http.MethodPut,
+			URL:        "/api/scans/priority?folder=/movies/Tenet",
+			StatusCode: http.StatusBadRequest,
+			Scan:       &processor.QueuedScan{Scan: autoscan.Scan{Folder: "/movies/Tenet", Priority: 1, Time: 1600000000}},
+		},
+		{
+			Name:       "Change priority to invalid priority",
+			Handler:    priorityHandler,
+			Method:     http.MethodPut,
+			URL:        "/api/scans/priority?folder=/movies/Tenet&priority=high",
+			StatusCode: http.StatusBadRequest,
+			Scan:       &processor.QueuedScan{Scan: autoscan.Scan{Folder: "/movies/Tenet", Priority: 1, Time: 1600000000}},
+		},
+		{
+			Name:       "Force scan",
+			Handler:    forceHandler,
+			Method:     http.MethodPost,
+			URL:        "/api/scans/process?folder=/movies/Tenet",
+			StatusCode: http.StatusOK,
+			Scan:       &processor.QueuedScan{Scan: autoscan.Scan{Folder: "/movies/Tenet", Priority: 1, Time: 1600000000}, Forced: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			proc := newTestProcessor(t, autoscan.Scan{Folder: "/movies/Tenet", Priority: 1, Time: 1600000000})
+
+			rec := httptest.NewRecorder()
+			tc.Handler(proc)(rec, httptest.NewRequest(tc.Method, tc.URL, http.NoBody))
+
+			if rec.Code != tc.StatusCode {
+				t.Errorf("expected status code %d, got %d", tc.StatusCode, rec.Code)
+			}
+
+			if tc.Method == http.MethodGet {
+				return
+			}
+
+			scan, err := proc.QueuedScan("/movies/Tenet")
+			switch {
+			case tc.Scan == nil && err == nil:
+				t.Errorf("expected scan to be removed, got %+v", scan)
+			case tc.Scan != nil && err != nil:
+				t.Fatal(err)
+			case tc.Scan != nil && (scan.Folder != tc.Scan.Folder || scan.Priority != tc.Scan.Priority ||
+				scan.Forced != tc.Scan.Forced):
+				t.Errorf("scans do not match: %+v vs %+v", scan, *tc.Scan)
+			}
+		})
+	}
+}
+
+func TestScansHandler(t *testing.T) {
+	proc := newTestProcessor(t,
+		autoscan.Scan{Folder: "/movies/Tenet", Priority: 1, Time: 1600000000},
+		autoscan.Scan{Folder: "/tv/Westworld", Priority: 2, Time: 1600000000},
+		autoscan.Scan{Folder: "/tv/Severance", Time: 1600000000},
+	)
+
+	type Test struct {
+		Name       string
+		URL        string
+		StatusCode int
+		Total      int
+		Folders    []string
+	}
+
+	testCases := []Test{
+		{
+			Name:       "All scans",
+			URL:        "/api/scans",
+			StatusCode: http.StatusOK,
+			Total:      3,
+			Folders:    []string{"/tv/Westworld", "/movies/Tenet", "/tv/Severance"},
+		},
+		{
+			Name:       "Prefix and page",
+			URL:        "/api/scans?prefix=/tv/&limit=1&offset=1",
+			StatusCode: http.StatusOK,
+			Total:      2,
+			Folders:    []string{"/tv/Severance"},
+		},
+		{
+			Name:       "Invalid limit",
+			URL:        "/api/scans?limit=0",
+			StatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			scansHandler(proc)(rec, httptest.NewRequest(http.MethodGet, tc.URL, http.NoBody))
+
+			if rec.Code != tc.StatusCode {
+				t.Fatalf("expected status code %d, got %d", tc.StatusCode, rec.Code)
+			}
+
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var resp scanListResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+
+			folders := make([]string, 0, len(resp.Scans))
+			for _, scan := range resp.Scans {
+				folders = append(folders, scan.Folder)
+			}
+
+			if resp.Total != tc.Total || len(folders) != len(tc.Folders) {
+				t.Fatalf("expected %d of %v, got %d of %v", tc.Total, tc.Folders, resp.Total, folders)
+			}
+
+			for i := range folders {
+				if folders[i] != tc.Folders[i] {
+					t.Errorf("expected %v, got %v", tc.Folders, folders)
+				}
+			}
+		})
+	}
+}
+
+func TestScanHandlersConflictWhileProcessing(t *testing.T) {
+	proc := newTestProcessor(t, autoscan.Scan{Folder: "/movies/Tenet", Time: 1600000000})
+
+	target := blockingTarget{
+		scanning: make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+
+	processed := make(chan error)
+	go func() {
+		processed <- proc.Process([]autoscan.Target{target})
+	}()
+
+	<-target.scanning
+
+	handlers := map[string]http.HandlerFunc{
+		http.MethodDelete: deleteScanHandler(proc),
+		http.MethodPut:    priorityHandler(proc),
+		http.MethodPost:   forceHandler(proc),
+	}
+
+	for method, handler := range handlers {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(method, "/api/scans?folder=/movies/Tenet&priority=5", http.NoBody))
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("%s: expected status code %d, got %d", method, http.StatusConflict, rec.Code)
+		}
+	}
+
+	close(target.release)
+	if err := <-processed; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := proc.QueuedScan("/movies/Tenet"); err == nil {
+		t.Error("expected scan to be processed")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -26,18 +27,30 @@ import (
 	"github.com/cloudbox/autoscan/internal/sqlite"
 	"github.com/cloudbox/autoscan/processor"
 	"github.com/cloudbox/autoscan/stats"
+	"github.com/cloudbox/autoscan/targets/audiobookshelf"
 	ast "github.com/cloudbox/autoscan/targets/autoscan"
 	"github.com/cloudbox/autoscan/targets/emby"
 	"github.com/cloudbox/autoscan/targets/jellyfin"
+	"github.com/cloudbox/autoscan/targets/kavita"
+	"github.com/cloudbox/autoscan/targets/kodi"
+	"github.com/cloudbox/autoscan/targets/komga"
 	"github.com/cloudbox/autoscan/targets/plex"
+	"github.com/cloudbox/autoscan/targets/subsonic"
+	"github.com/cloudbox/autoscan/targets/webhook"
 	atrain "github.com/cloudbox/autoscan/triggers/a_train"
+	"github.com/cloudbox/autoscan/triggers/bazarr"
 	"github.com/cloudbox/autoscan/triggers/bernard"
+	"github.com/cloudbox/autoscan/triggers/generic"
 	"github.com/cloudbox/autoscan/triggers/inotify"
 	"github.com/cloudbox/autoscan/triggers/lidarr"
 	"github.com/cloudbox/autoscan/triggers/manual"
+	"github.com/cloudbox/autoscan/triggers/poll"
 	"github.com/cloudbox/autoscan/triggers/radarr"
+	"github.com/cloudbox/autoscan/triggers/rclone"
 	"github.com/cloudbox/autoscan/triggers/readarr"
 	"github.com/cloudbox/autoscan/triggers/sonarr"
+	"github.com/cloudbox/autoscan/triggers/tdarr"
+	"github.com/cloudbox/autoscan/triggers/whisparr"
 )
 
 const (
@@ -45,8 +58,11 @@ const (
 	logMaxAgeDays = 14
 	logMaxBackups = 5
 
-	defaultScanDelay = 5 * time.Second
-	defaultPort      = 3030
+	defaultScanDelay   = 5 * time.Second
+	defaultWorkers     = 1
+	defaultPort        = 3030
+	defaultMaxAttempts = 5
+	defaultRetryDelay  = 1 * time.Minute
 
 	serverTimeout = 30 * time.Second
 
@@ -59,21 +75,33 @@ type authConfig struct {
 }
 
 type triggersConfig struct {
-	Manual  manual.Config    `yaml:"manual"`
-	ATrain  atrain.Config    `yaml:"a-train"`
-	Bernard []bernard.Config `yaml:"bernard"`
-	Inotify []inotify.Config `yaml:"inotify"`
-	Lidarr  []lidarr.Config  `yaml:"lidarr"`
-	Radarr  []radarr.Config  `yaml:"radarr"`
-	Readarr []readarr.Config `yaml:"readarr"`
-	Sonarr  []sonarr.Config  `yaml:"sonarr"`
+	Manual   manual.Config     `yaml:"manual"`
+	ATrain   atrain.Config     `yaml:"a-train"`
+	Bazarr   []bazarr.Config   `yaml:"bazarr"`
+	Bernard  []bernard.Config  `yaml:"bernard"`
+	Generic  []generic.Config  `yaml:"generic"`
+	Inotify  []inotify.Config  `yaml:"inotify"`
+	Lidarr   []lidarr.Config   `yaml:"lidarr"`
+	Poll     []poll.Config     `yaml:"poll"`
+	Rclone   []rclone.Config   `yaml:"rclone"`
+	Radarr   []radarr.Config   `yaml:"radarr"`
+	Readarr  []readarr.Config  `yaml:"readarr"`
+	Sonarr   []sonarr.Config   `yaml:"sonarr"`
+	Tdarr    []tdarr.Config    `yaml:"tdarr"`
+	Whisparr []whisparr.Config `yaml:"whisparr"`
 }
 
 type targetsConfig struct {
-	Autoscan []ast.Config      `yaml:"autoscan"`
-	Emby     []emby.Config     `yaml:"emby"`
-	Jellyfin []jellyfin.Config `yaml:"jellyfin"`
-	Plex     []plex.Config     `yaml:"plex"`
+	Audiobookshelf []audiobookshelf.Config `yaml:"audiobookshelf"`
+	Autoscan       []ast.Config            `yaml:"autoscan"`
+	Emby           []emby.Config           `yaml:"emby"`
+	Jellyfin       []jellyfin.Config       `yaml:"jellyfin"`
+	Kavita         []kavita.Config         `yaml:"kavita"`
+	Kodi           []kodi.Config           `yaml:"kodi"`
+	Komga          []komga.Config          `yaml:"komga"`
+	Plex           []plex.Config           `yaml:"plex"`
+	Subsonic       []subsonic.Config       `yaml:"subsonic"`
+	Webhook        []webhook.Config        `yaml:"webhook"`
 }
 
 type config struct {
@@ -84,6 +112,11 @@ type config struct {
 	ScanDelay  time.Duration `yaml:"scan-delay"`
 	ScanStats  time.Duration `yaml:"scan-stats"`
 	Anchors    []string      `yaml:"anchors"`
+	Workers    int           `yaml:"workers"`
+
+	// Retry configuration for scans which keep failing
+	MaxAttempts int           `yaml:"max-attempts"`
+	RetryDelay  time.Duration `yaml:"retry-delay"`
 
 	// Authentication for autoscan.HTTPTrigger
 	Auth authConfig `yaml:"authentication"`
@@ -180,7 +213,7 @@ func main() {
 	}
 
 	// daemon triggers
-	initDaemonTriggers(cfg, db, proc.Add)
+	initDaemonTriggers(cfg, db, proc)
 
 	// http triggers
 	router := getRouter(cfg, proc)
@@ -189,22 +222,34 @@ func main() {
 
 	log.Info().
 		Int("manual", 1).
+		Int("bazarr", len(cfg.Triggers.Bazarr)).
 		Int("bernard", len(cfg.Triggers.Bernard)).
+		Int("generic", len(cfg.Triggers.Generic)).
 		Int("inotify", len(cfg.Triggers.Inotify)).
 		Int("lidarr", len(cfg.Triggers.Lidarr)).
+		Int("poll", len(cfg.Triggers.Poll)).
+		Int("rclone", len(cfg.Triggers.Rclone)).
 		Int("radarr", len(cfg.Triggers.Radarr)).
 		Int("readarr", len(cfg.Triggers.Readarr)).
 		Int("sonarr", len(cfg.Triggers.Sonarr)).
+		Int("tdarr", len(cfg.Triggers.Tdarr)).
+		Int("whisparr", len(cfg.Triggers.Whisparr)).
 		Msg("Triggers Initialised")
 
 	// targets
-	targets := initTargets(cfg)
+	targets := initTargets(cfg, proc)
 
 	log.Info().
 		Int("autoscan", len(cfg.Targets.Autoscan)).
 		Int("plex", len(cfg.Targets.Plex)).
 		Int("emby", len(cfg.Targets.Emby)).
 		Int("jellyfin", len(cfg.Targets.Jellyfin)).
+		Int("kodi", len(cfg.Targets.Kodi)).
+		Int("audiobookshelf", len(cfg.Targets.Audiobookshelf)).
+		Int("kavita", len(cfg.Targets.Kavita)).
+		Int("komga", len(cfg.Targets.Komga)).
+		Int("subsonic", len(cfg.Targets.Subsonic)).
+		Int("webhook", len(cfg.Targets.Webhook)).
 		Msg("Targets Initialised")
 
 	// scan stats
@@ -221,17 +266,21 @@ func main() {
 
 	// processor
 	log.Info().Msg("Processor Started")
-	runScanLoop(proc, targets, cfg.ScanDelay)
+	runScanLoop(proc, targets, cfg.Workers)
 }
 
 // initProcessor creates and returns the scan processor from config and database.
 // Calls log.Fatal on initialisation error.
 func initProcessor(cfg config, db *sqlite.DB, procStats *stats.Stats) *processor.Processor {
 	proc, err := processor.New(processor.Config{
-		Anchors:    cfg.Anchors,
-		MinimumAge: cfg.MinimumAge,
-		Stats:      procStats,
-		Db:         db,
+		Anchors:     cfg.Anchors,
+		MinimumAge:  cfg.MinimumAge,
+		Stats:       procStats,
+		MaxAttempts: cfg.MaxAttempts,
+		RetryDelay:  cfg.RetryDelay,
+		ScanDelay:   cfg.ScanDelay,
+		Coalesce:    coalesceEnabled(cfg.Targets),
+		Db:          db,
 	})
 	if err != nil {
 		log.Fatal().
@@ -242,11 +291,51 @@ func initProcessor(cfg config, db *sqlite.DB, procStats *stats.Stats) *processor
 	log.Info().
 		Stringer("min_age", cfg.MinimumAge).
 		Strs("anchors", cfg.Anchors).
+		Int("max_attempts", cfg.MaxAttempts).
+		Stringer("retry_delay", cfg.RetryDelay).
+		Stringer("scan_delay", cfg.ScanDelay).
+		Int("workers", cfg.Workers).
+		Bool("coalesce", coalesceEnabled(cfg.Targets)).
 		Msg("Processor Initialised")
 
 	return proc
 }
 
+// coalesceEnabled reports whether any target accepts coalesced scans.
+func coalesceEnabled(targets targetsConfig) bool {
+	for _, t := range targets.Plex {
+		if t.Coalesce {
+			return true
+		}
+	}
+
+	for _, t := range targets.Emby {
+		if t.Coalesce {
+			return true
+		}
+	}
+
+	for _, t := range targets.Jellyfin {
+		if t.Coalesce {
+			return true
+		}
+	}
+
+	for _, t := range targets.Kodi {
+		if t.Coalesce {
+			return true
+		}
+	}
+
+	for _, t := range targets.Kavita {
+		if t.Coalesce {
+			return true
+		}
+	}
+
+	return false
+}
+
 // startHTTPServers binds a listener per host address, then serves in background
 // goroutines. The function returns only after every listener has successfully
 // bound, so callers can rely on the ports being open. Calls log.Fatal on bind
@@ -321,11 +410,14 @@ func loadConfig() config {
 
 	// set default values
 	cfg := config{
-		MinimumAge: 10 * time.Minute,
-		ScanDelay:  defaultScanDelay,
-		ScanStats:  1 * time.Hour,
-		Host:       []string{""},
-		Port:       defaultPort,
+		MinimumAge:  10 * time.Minute,
+		ScanDelay:   defaultScanDelay,
+		ScanStats:   1 * time.Hour,
+		Workers:     defaultWorkers,
+		MaxAttempts: defaultMaxAttempts,
+		RetryDelay:  defaultRetryDelay,
+		Host:        []string{""},
+		Port:        defaultPort,
 	}
 
 	decoder := yaml.NewDecoder(file)
@@ -339,6 +431,18 @@ func loadConfig() config {
 			Msg("Config Decode Failed")
 	}
 
+	if cfg.MaxAttempts < 1 {
+		log.Fatal().
+			Int("max_attempts", cfg.MaxAttempts).
+			Msg("Invalid Max Attempts")
+	}
+
+	if cfg.Workers < 1 {
+		log.Fatal().
+			Int("workers", cfg.Workers).
+			Msg("Invalid Workers")
+	}
+
 	return cfg
 }
 
@@ -377,9 +481,9 @@ func setupLogger() {
 	}
 }
 
-// initDaemonTriggers starts the bernard and inotify background triggers.
+// initDaemonTriggers starts the bernard, inotify, poll and rclone background triggers.
 // Calls log.Fatal on any initialisation error.
-func initDaemonTriggers(cfg config, db *sqlite.DB, add autoscan.ProcessorFunc) {
+func initDaemonTriggers(cfg config, db *sqlite.DB, proc *processor.Processor) {
 	for _, t := range cfg.Triggers.Bernard {
 		trigger, err := bernard.New(t, db.RW())
 		if err != nil {
@@ -389,7 +493,7 @@ func initDaemonTriggers(cfg config, db *sqlite.DB, add autoscan.ProcessorFunc) {
 				Msg("Trigger Init Failed")
 		}
 
-		go trigger(add)
+		go trigger(proc.AddFrom("bernard"))
 	}
 
 	for _, t := range cfg.Triggers.Inotify {
@@ -401,14 +505,41 @@ func initDaemonTriggers(cfg config, db *sqlite.DB, add autoscan.ProcessorFunc) {
 				Msg("Trigger Init Failed")
 		}
 
-		go trigger(add)
+		go trigger(proc.AddFrom("inotify"))
+	}
+
+	for _, t := range cfg.Triggers.Poll {
+		trigger, err := poll.New(t, db.RW())
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Str("trigger", "poll").
+				Msg("Trigger Init Failed")
+		}
+
+		go trigger(proc.AddFrom("poll"))
+	}
+
+	for _, t := range cfg.Triggers.Rclone {
+		trigger, err := rclone.New(t, db.RW())
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Str("trigger", "rclone").
+				Msg("Trigger Init Failed")
+		}
+
+		go trigger(proc.AddFrom("rclone"))
 	}
 }
 
-// initTargets builds the list of scan targets from the config.
+// initTargets builds the list of scan targets from the config
+// and registers their limits with the processor.
 // Calls log.Fatal on any initialisation error.
-func initTargets(cfg config) []autoscan.Target {
-	targetCount := len(cfg.Targets.Autoscan) + len(cfg.Targets.Plex) + len(cfg.Targets.Emby) + len(cfg.Targets.Jellyfin)
+func initTargets(cfg config, proc *processor.Processor) []autoscan.Target {
+	targetCount := len(cfg.Targets.Autoscan) + len(cfg.Targets.Plex) + len(cfg.Targets.Emby) + len(cfg.Targets.Jellyfin) +
+		len(cfg.Targets.Kodi) + len(cfg.Targets.Audiobookshelf) + len(cfg.Targets.Kavita) + len(cfg.Targets.Komga) +
+		len(cfg.Targets.Subsonic) + len(cfg.Targets.Webhook)
 	targets := make([]autoscan.Target, 0, targetCount)
 
 	for _, t := range cfg.Targets.Autoscan {
@@ -421,6 +552,7 @@ func initTargets(cfg config) []autoscan.Target {
 				Msg("Target Init Failed")
 		}
 
+		proc.Limit(target.ID(), t.Limits)
 		targets = append(targets, target)
 	}
 
@@ -434,6 +566,7 @@ func initTargets(cfg config) []autoscan.Target {
 				Msg("Target Init Failed")
 		}
 
+		proc.Limit(target.ID(), t.Limits)
 		targets = append(targets, target)
 	}
 
@@ -447,6 +580,7 @@ func initTargets(cfg config) []autoscan.Target {
 				Msg("Target Init Failed")
 		}
 
+		proc.Limit(target.ID(), t.Limits)
 		targets = append(targets, target)
 	}
 
@@ -460,54 +594,147 @@ func initTargets(cfg config) []autoscan.Target {
 				Msg("Target Init Failed")
 		}
 
+		proc.Limit(target.ID(), t.Limits)
+		targets = append(targets, target)
+	}
+
+	for _, t := range cfg.Targets.Kodi {
+		target, err := kodi.New(t)
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Str("target", "kodi").
+				Str("target_url", t.URL).
+				Msg("Target Init Failed")
+		}
+
+		proc.Limit(target.ID(), t.Limits)
+		targets = append(targets, target)
+	}
+
+	for _, t := range cfg.Targets.Audiobookshelf {
+		target, err := audiobookshelf.New(t)
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Str("target", "audiobookshelf").
+				Str("target_url", t.URL).
+				Msg("Target Init Failed")
+		}
+
+		proc.Limit(target.ID(), t.Limits)
+		targets = append(targets, target)
+	}
+
+	for _, t := range cfg.Targets.Kavita {
+		target, err := kavita.New(t)
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Str("target", "kavita").
+				Str("target_url", t.URL).
+				Msg("Target Init Failed")
+		}
+
+		proc.Limit(target.ID(), t.Limits)
+		targets = append(targets, target)
+	}
+
+	for _, t := range cfg.Targets.Komga {
+		target, err := komga.New(t)
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Str("target", "komga").
+				Str("target_url", t.URL).
+				Msg("Target Init Failed")
+		}
+
+		proc.Limit(target.ID(), t.Limits)
 		targets = append(targets, target)
 	}
 
+	for _, t := range cfg.Targets.Subsonic {
+		target, err := subsonic.New(t)
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Str("target", "subsonic").
+				Str("target_url", t.URL).
+				Msg("Target Init Failed")
+		}
+
+		proc.Limit(target.ID(), t.Limits)
+		targets = append(targets, target)
+	}
+
+	for _, t := range cfg.Targets.Webhook {
+		target, err := webhook.New(t)
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Str("target", "webhook").
+				Str("target_url", t.URL).
+				Msg("Target Init Failed")
+		}
+
+		proc.Limit(target.ID(), t.Limits)
+		targets = append(targets, target)
+	}
+
+	checkTargetIDs(targets)
 	return targets
 }
 
-// runScanLoop runs the main processing loop until the process exits.
-// It checks anchor availability and target availability before processing,
-// and backs off on transient errors.
-func runScanLoop(proc *processor.Processor, targets []autoscan.Target, scanDelay time.Duration) {
-	targetsAvailable := false
-	targetsSize := len(targets)
-
-	for {
-		// exit when no targets setup
-		if targetsSize == 0 {
-			log.Fatal().Msg("No Targets")
+// checkTargetIDs ensures every target has a unique ID, as the processor
+// tracks delivery state per target ID.
+// Calls log.Fatal when two targets share an ID.
+func checkTargetIDs(targets []autoscan.Target) {
+	seen := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		if seen[target.ID()] {
+			log.Fatal().
+				Str("target_id", target.ID()).
+				Msg("Duplicate Target")
 		}
 
+		seen[target.ID()] = true
+	}
+}
+
+// runScanLoop runs the given amount of processing workers until the process exits.
+func runScanLoop(proc *processor.Processor, targets []autoscan.Target, workers int) {
+	// exit when no targets setup
+	if len(targets) == 0 {
+		log.Fatal().Msg("No Targets")
+	}
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			runWorker(proc, targets)
+		})
+	}
+
+	wg.Wait()
+}
+
+// runWorker processes scans until the process exits.
+// It checks anchor availability before processing, and backs off on transient
+// errors. The processor holds back scans for unavailable targets, and the
+// targets' limits pace the scans.
+func runWorker(proc *processor.Processor, targets []autoscan.Target) {
+	for {
 		// anchor availability gate — if mounts are offline, skip everything
 		if !proc.CheckAnchors() {
 			time.Sleep(noScansDelay)
 			continue
 		}
 
-		// target availability checker
-		if !targetsAvailable {
-			err := proc.CheckAvailability(targets)
-			switch {
-			case err == nil:
-				targetsAvailable = true
-
-			case errors.Is(err, autoscan.ErrFatal):
-				log.Fatal().Err(err).Msg("Target Check Failed")
-
-			default:
-				log.Error().Err(err).Msg("Targets Unavailable")
-				time.Sleep(noScansDelay)
-				continue
-			}
-		}
-
 		// process scans
 		err := proc.Process(targets)
 		switch {
 		case err == nil:
-			// Sleep scan-delay between successful requests to reduce the load on targets.
-			time.Sleep(scanDelay)
 
 		case errors.Is(err, autoscan.ErrNoScans):
 			// No scans currently available, let's wait a couple of seconds
@@ -515,9 +742,8 @@ func runScanLoop(proc *processor.Processor, targets []autoscan.Target, scanDelay
 			time.Sleep(noScansDelay)
 
 		case errors.Is(err, autoscan.ErrTargetUnavailable):
-			proc.Stats().Retried.Add(1)
-			targetsAvailable = false
-			log.Error().Err(err).Msg("Targets Unavailable")
+			// All targets are unavailable, their breakers check them again
+			log.Trace().Err(err).Msg("Targets Unavailable")
 			time.Sleep(noScansDelay)
 
 		case errors.Is(err, autoscan.ErrFatal):
@@ -11,11 +11,15 @@ import (
 
 	"github.com/cloudbox/autoscan/processor"
 	atrain "github.com/cloudbox/autoscan/triggers/a_train"
+	"github.com/cloudbox/autoscan/triggers/bazarr"
+	"github.com/cloudbox/autoscan/triggers/generic"
 	"github.com/cloudbox/autoscan/triggers/lidarr"
 	"github.com/cloudbox/autoscan/triggers/manual"
 	"github.com/cloudbox/autoscan/triggers/radarr"
 	"github.com/cloudbox/autoscan/triggers/readarr"
 	"github.com/cloudbox/autoscan/triggers/sonarr"
+	"github.com/cloudbox/autoscan/triggers/tdarr"
+	"github.com/cloudbox/autoscan/triggers/whisparr"
 )
 
 func pattern(name string) string {
@@ -49,6 +53,33 @@ func getRouter(cfg config, proc *processor.Processor) chi.Router {
 	// Health check
 	mux.Get("/health", healthHandler)
 
+	// Prometheus metrics
+	mux.Group(func(sub chi.Router) {
+		// Use Basic Auth middleware if username and password are set.
+		if cfg.Auth.Username != "" && cfg.Auth.Password != "" {
+			sub.Use(middleware.BasicAuth("Autoscan 1.x", createCredentials(cfg)))
+		}
+
+		sub.Get("/metrics", metricsHandler(proc))
+	})
+
+	// Queue management
+	mux.Route("/api", func(sub chi.Router) {
+		// Use Basic Auth middleware if username and password are set.
+		if cfg.Auth.Username != "" && cfg.Auth.Password != "" {
+			sub.Use(middleware.BasicAuth("Autoscan 1.x", createCredentials(cfg)))
+		}
+
+		sub.Get("/scans", scansHandler(proc))
+		sub.Get("/scans/scan", scanHandler(proc))
+		sub.Delete("/scans/scan", deleteScanHandler(proc))
+		sub.Put("/scans/priority", priorityHandler(proc))
+		sub.Post("/scans/process", forceHandler(proc))
+
+		sub.Get("/failed", failedScansHandler(proc))
+		sub.Post("/failed/requeue", requeueHandler(proc))
+	})
+
 	// HTTP-Triggers
 	mux.Route("/triggers", func(sub chi.Router) {
 		// Use Basic Auth middleware if username and password are set.
@@ -63,7 +94,7 @@ func getRouter(cfg config, proc *processor.Processor) chi.Router {
 				log.Fatal().Err(err).Str("trigger", "a-train").Msg("Trigger Init Failed")
 			}
 
-			sub.Post("/{drive}", trigger(proc.Add).ServeHTTP)
+			sub.Post("/{drive}", trigger(proc.AddFrom("a-train")).ServeHTTP)
 		})
 
 		// Mixed-style Manual HTTP-trigger
@@ -73,7 +104,7 @@ func getRouter(cfg config, proc *processor.Processor) chi.Router {
 				log.Fatal().Err(err).Str("trigger", "manual").Msg("Trigger Init Failed")
 			}
 
-			sub.HandleFunc("/", trigger(proc.Add).ServeHTTP)
+			sub.HandleFunc("/", trigger(proc.AddFrom("manual")).ServeHTTP)
 		})
 
 		// OLD-style HTTP-triggers. Can be converted to the /{trigger}/{id} format in a 2.0 release.
@@ -83,7 +114,7 @@ func getRouter(cfg config, proc *processor.Processor) chi.Router {
 				log.Fatal().Err(err).Str("trigger", t.Name).Msg("Trigger Init Failed")
 			}
 
-			sub.Post(pattern(t.Name), trigger(proc.Add).ServeHTTP)
+			sub.Post(pattern(t.Name), trigger(proc.AddFrom(t.Name)).ServeHTTP)
 		}
 
 		for _, t := range cfg.Triggers.Radarr {
@@ -92,7 +123,7 @@ func getRouter(cfg config, proc *processor.Processor) chi.Router {
 				log.Fatal().Err(err).Str("trigger", t.Name).Msg("Trigger Init Failed")
 			}
 
-			sub.Post(pattern(t.Name), trigger(proc.Add).ServeHTTP)
+			sub.Post(pattern(t.Name), trigger(proc.AddFrom(t.Name)).ServeHTTP)
 		}
 
 		for _, t := range cfg.Triggers.Readarr {
@@ -101,7 +132,7 @@ func getRouter(cfg config, proc *processor.Processor) chi.Router {
 				log.Fatal().Err(err).Str("trigger", t.Name).Msg("Trigger Init Failed")
 			}
 
-			sub.Post(pattern(t.Name), trigger(proc.Add).ServeHTTP)
+			sub.Post(pattern(t.Name), trigger(proc.AddFrom(t.Name)).ServeHTTP)
 		}
 
 		for _, t := range cfg.Triggers.Sonarr {
@@ -110,7 +141,43 @@ func getRouter(cfg config, proc *processor.Processor) chi.Router {
 				log.Fatal().Err(err).Str("trigger", t.Name).Msg("Trigger Init Failed")
 			}
 
-			sub.Post(pattern(t.Name), trigger(proc.Add).ServeHTTP)
+			sub.Post(pattern(t.Name), trigger(proc.AddFrom(t.Name)).ServeHTTP)
+		}
+
+		for _, t := range cfg.Triggers.Whisparr {
+			trigger, err := whisparr.New(t)
+			if err != nil {
+				log.Fatal().Err(err).Str("trigger", t.Name).Msg("Trigger Init Failed")
+			}
+
+			sub.Post(pattern(t.Name), trigger(proc.AddFrom(t.Name)).ServeHTTP)
+		}
+
+		for _, t := range cfg.Triggers.Bazarr {
+			trigger, err := bazarr.New(t)
+			if err != nil {
+				log.Fatal().Err(err).Str("trigger", t.Name).Msg("Trigger Init Failed")
+			}
+
+			sub.Post(pattern(t.Name), trigger(proc.AddFrom(t.Name)).ServeHTTP)
+		}
+
+		for _, t := range cfg.Triggers.Tdarr {
+			trigger, err := tdarr.New(t)
+			if err != nil {
+				log.Fatal().Err(err).Str("trigger", t.Name).Msg("Trigger Init Failed")
+			}
+
+			sub.Post(pattern(t.Name), trigger(proc.AddFrom(t.Name)).ServeHTTP)
+		}
+
+		for _, t := range cfg.Triggers.Generic {
+			trigger, err := generic.New(t)
+			if err != nil {
+				log.Fatal().Err(err).Str("trigger", t.Name).Msg("Trigger Init Failed")
+			}
+
+			sub.Post(pattern(t.Name), trigger(proc.AddFrom(t.Name)).ServeHTTP)
 		}
 	})
 
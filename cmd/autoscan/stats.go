@@ -3,9 +3,11 @@ package main
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/coreos/go-systemd/v22/daemon"
+	"github.com/rs/zerolog/hlog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/cloudbox/autoscan"
@@ -26,11 +28,12 @@ func scanStats(st *stats.Stats, proc *processor.Processor, interval time.Duratio
 				Int64("received", snap.Received).
 				Int64("processed", snap.Processed).
 				Int64("retried", snap.Retried).
+				Int64("failed", snap.Failed).
 				Msg("Scan Stats")
 
 			status := fmt.Sprintf(
-				"STATUS=remaining: %d | received: %d | processed: %d | retried: %d",
-				remaining, snap.Received, snap.Processed, snap.Retried,
+				"STATUS=remaining: %d | received: %d | processed: %d | retried: %d | failed: %d",
+				remaining, snap.Received, snap.Processed, snap.Retried, snap.Failed,
 			)
 			_, _ = daemon.SdNotify(false, status)
 
@@ -48,3 +51,25 @@ func scanStats(st *stats.Stats, proc *processor.Processor, interval time.Duratio
 		}
 	}
 }
+
+// metricsHandler exposes the scan stats in the Prometheus text format.
+func metricsHandler(proc *processor.Processor) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		rlog := hlog.FromRequest(r)
+
+		remaining, err := proc.ScansRemaining()
+		if err != nil {
+			rlog.Error().Err(err).Msg("Scans Remaining Failed")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		st := proc.Stats()
+		st.Queued.Store(int64(remaining))
+
+		rw.Header().Set("Content-Type", stats.ContentType)
+		if err := st.WritePrometheus(rw); err != nil {
+			rlog.Error().Err(err).Msg("Metrics Write Failed")
+		}
+	}
+}
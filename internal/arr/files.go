@@ -0,0 +1,27 @@
+package arr
+
+import (
+	"path"
+	"slices"
+)
+
+// FilePaths returns the folder→files mapping of the given absolute file paths.
+// Empty paths are skipped and every file is included once.
+func FilePaths(files ...string) map[string][]string {
+	paths := make(map[string][]string)
+
+	for _, f := range files {
+		if f == "" {
+			continue
+		}
+
+		// Use path.Dir to get the directory in which the file is located.
+		// Use path.Base to get the filename.
+		folder, file := path.Dir(f), path.Base(f)
+		if !slices.Contains(paths[folder], file) {
+			paths[folder] = append(paths[folder], file)
+		}
+	}
+
+	return paths
+}
@@ -0,0 +1,25 @@
+package arr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilePaths(t *testing.T) {
+	files := []string{
+		"/Music/blink-182/California (2016)/CD 01/01 - Cynical.mp3",
+		"/Music/blink-182/California (2016)/CD 01/02 - Bored to Death.mp3",
+		"/Music/blink-182/California (2016)/CD 01/01 - Cynical.mp3",
+		"",
+		"/Music/blink-182/California (2016)/CD 02/01 - Parking Lot.mp3",
+	}
+
+	want := map[string][]string{
+		"/Music/blink-182/California (2016)/CD 01": {"01 - Cynical.mp3", "02 - Bored to Death.mp3"},
+		"/Music/blink-182/California (2016)/CD 02": {"01 - Parking Lot.mp3"},
+	}
+
+	if got := FilePaths(files...); !reflect.DeepEqual(got, want) {
+		t.Errorf("FilePaths() = %v, want %v", got, want)
+	}
+}
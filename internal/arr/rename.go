@@ -0,0 +1,20 @@
+// Package arr provides helpers shared by the triggers of the -arrs.
+package arr
+
+// RenamedFile is a file which was moved from PreviousPath to Path.
+// Both paths are absolute paths as reported by the -arr.
+type RenamedFile struct {
+	PreviousPath string
+	Path         string
+}
+
+// RenamedPaths returns the folder→files mapping of the previous and the current
+// location of every renamed file. Every file is included once.
+func RenamedPaths(files []RenamedFile) map[string][]string {
+	paths := make([]string, 0, 2*len(files))
+	for _, f := range files {
+		paths = append(paths, f.PreviousPath, f.Path)
+	}
+
+	return FilePaths(paths...)
+}
@@ -0,0 +1,38 @@
+package arr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenamedPaths(t *testing.T) {
+	files := []RenamedFile{
+		{
+			PreviousPath: "/TV/Westworld/Season 1/Westworld.S01E01.mkv",
+			Path:         "/TV/Westworld [imdb:tt0475784]/Season 1/Westworld.S01E01.mkv",
+		},
+		{
+			PreviousPath: "/TV/Westworld/Season 1/Westworld.S01E02.mkv",
+			Path:         "/TV/Westworld [imdb:tt0475784]/Season 1/Westworld.S01E02.mkv",
+		},
+		{
+			// renamed again, so both paths are already included
+			PreviousPath: "/TV/Westworld/Season 1/Westworld.S01E02.mkv",
+			Path:         "/TV/Westworld [imdb:tt0475784]/Season 1/Westworld.S01E02.mkv",
+		},
+		{
+			// the previous path is missing
+			Path: "/TV/Westworld [imdb:tt0475784]/Season 2/Westworld.S02E01.mkv",
+		},
+	}
+
+	want := map[string][]string{
+		"/TV/Westworld/Season 1":                  {"Westworld.S01E01.mkv", "Westworld.S01E02.mkv"},
+		"/TV/Westworld [imdb:tt0475784]/Season 1": {"Westworld.S01E01.mkv", "Westworld.S01E02.mkv"},
+		"/TV/Westworld [imdb:tt0475784]/Season 2": {"Westworld.S02E01.mkv"},
+	}
+
+	if got := RenamedPaths(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("RenamedPaths() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+// Package snapshot provides the folder snapshots shared by the polling triggers.
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Folder is the snapshot of a single directory.
+//
+// Entries holds the sorted directory listing, as returned by DirEntry and
+// FileEntry. Files are listed with their size and mtime, so a replaced
+// file changes the listing as well.
+type Folder struct {
+	Path    string
+	ModTime int64
+	Entries []string
+}
+
+// DirEntry returns the entry of a subdirectory.
+func DirEntry(name string) string {
+	return name + "/"
+}
+
+// FileEntry returns the entry of a file.
+func FileEntry(name string, size, modTime int64) string {
+	return fmt.Sprintf("%s %d %d", name, size, modTime)
+}
+
+// Read returns the snapshot of the directory and the names of its subdirectories.
+// Symlinked directories are listed as files and are not followed.
+func Read(dir string) (Folder, []string, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return Folder{}, nil, fmt.Errorf("stat: %w", err)
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return Folder{}, nil, fmt.Errorf("read dir: %w", err)
+	}
+
+	f := Folder{
+		Path:    dir,
+		ModTime: fi.ModTime().UnixNano(),
+		Entries: make([]string, 0, len(dirEntries)),
+	}
+
+	var subdirs []string
+	for _, e := range dirEntries {
+		if e.IsDir() {
+			f.Entries = append(f.Entries, DirEntry(e.Name()))
+			subdirs = append(subdirs, e.Name())
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			// removed since the directory was read
+			continue
+		}
+
+		f.Entries = append(f.Entries, FileEntry(e.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+
+	slices.Sort(f.Entries)
+	return f, subdirs, nil
+}
+
+// Within reports whether path is dir or one of its descendants.
+func Within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// changed reports whether the contents of the folder changed since the
+// previous snapshot. Added subdirectories which are part of the current
+// snapshot are scanned on their own, so they do not count as a change
+// of the folder itself.
+func (f Folder) changed(prev Folder, current map[string]Folder) bool {
+	if slices.Equal(f.Entries, prev.Entries) {
+		return f.ModTime != prev.ModTime
+	}
+
+	for _, e := range prev.Entries {
+		if _, ok := slices.BinarySearch(f.Entries, e); !ok {
+			// removed or modified
+			return true
+		}
+	}
+
+	for _, e := range f.Entries {
+		if _, ok := slices.BinarySearch(prev.Entries, e); ok {
+			continue
+		}
+
+		name, isDir := strings.CutSuffix(e, "/")
+		if _, walked := current[filepath.Join(f.Path, name)]; !isDir || !walked {
+			// added file, or added folder which is not part of the snapshot
+			return true
+		}
+	}
+
+	return false
+}
+
+// Diff compares the current snapshot of a root with the previous one.
+// Returns the folders to scan, the folders to store and the folders which were removed.
+func Diff(prev, current map[string]Folder) (scan []string, updated []Folder, removed []string) {
+	for path, f := range current {
+		p, ok := prev[path]
+		switch {
+		case !ok:
+			scan = append(scan, path)
+			updated = append(updated, f)
+		case f.ModTime != p.ModTime || !slices.Equal(f.Entries, p.Entries):
+			if f.changed(p, current) {
+				scan = append(scan, path)
+			}
+			updated = append(updated, f)
+		}
+	}
+
+	for path := range prev {
+		if _, ok := current[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+
+	slices.Sort(scan)
+	return scan, updated, removed
+}
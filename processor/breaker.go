@@ -0,0 +1,202 @@
+package processor
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// breakerCooldown is the delay before an unavailable target is checked again.
+// Scans which could not be delivered to an unavailable target are postponed
+// by the same delay.
+const breakerCooldown = 15 * time.Second
+
+type breakerState int
+
+const (
+	// breakerClosed lets all scans through to the target.
+	breakerClosed breakerState = iota
+	// breakerOpen holds back all scans until the target is available again.
+	breakerOpen
+	// breakerHalfOpen lets a single trial scan through to the target.
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker tracks the availability of a single target.
+//
+// A breaker opens when the target reports ErrTargetUnavailable. Once the
+// cooldown has passed, the target's Available method is checked. When the
+// target is available again, the breaker lets a single trial scan through,
+// and closes when that scan reaches the target.
+type breaker struct {
+	mu        sync.Mutex
+	state     breakerState
+	openUntil time.Time
+	probing   bool // Available is being checked
+	trial     bool // the trial scan is in flight
+}
+
+// allow reports whether a scan may be sent to the target.
+// An open breaker checks the target's availability once its cooldown passed.
+func (b *breaker) allow(probe func() error) bool {
+	b.mu.Lock()
+	switch {
+	case b.state == breakerClosed:
+		b.mu.Unlock()
+		return true
+
+	case b.state == breakerHalfOpen && !b.trial:
+		b.trial = true
+		b.mu.Unlock()
+		return true
+
+	case b.state == breakerOpen && !b.probing && !now().Before(b.openUntil):
+		b.probing = true
+		b.mu.Unlock()
+
+	default:
+		b.mu.Unlock()
+		return false
+	}
+
+	// Check the availability without holding the lock.
+	err := probe()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if err != nil {
+		b.openUntil = now().Add(breakerCooldown)
+		return false
+	}
+
+	b.state = breakerHalfOpen
+	b.trial = true
+	return true
+}
+
+// blocked reports whether the breaker is open and not yet due for an availability check.
+func (b *breaker) blocked() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state == breakerOpen && now().Before(b.openUntil)
+}
+
+// current returns the state of the breaker.
+func (b *breaker) current() breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// record updates the breaker with the outcome of a scan sent to the target.
+// Returns the previous and the new state of the breaker.
+func (b *breaker) record(err error) (breakerState, breakerState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prev := b.state
+	b.trial = false
+
+	if errors.Is(err, autoscan.ErrTargetUnavailable) {
+		b.state = breakerOpen
+		b.openUntil = now().Add(breakerCooldown)
+	} else if b.state == breakerHalfOpen {
+		// Any other outcome means the target responded.
+		b.state = breakerClosed
+	}
+
+	return prev, b.state
+}
+
+// breaker returns the breaker of the target, creating a closed one when needed.
+func (p *Processor) breaker(targetID string) *breaker {
+	p.breakersMu.Lock()
+	defer p.breakersMu.Unlock()
+
+	if p.breakers == nil {
+		p.breakers = make(map[string]*breaker)
+	}
+
+	b, ok := p.breakers[targetID]
+	if !ok {
+		b = &breaker{}
+		p.breakers[targetID] = b
+	}
+
+	return b
+}
+
+// allBlocked reports whether the breakers of all targets hold back scans.
+func (p *Processor) allBlocked(targets []autoscan.Target) bool {
+	for _, t := range targets {
+		if !p.breaker(t.ID()).blocked() {
+			return false
+		}
+	}
+
+	return len(targets) > 0
+}
+
+// allowTargets splits the targets into the targets whose breaker lets the scan
+// through, and the targets which are deferred until they are available again.
+// The allowed targets must be sent the scan, as a half-open breaker waits for
+// the outcome of its trial scan.
+func (p *Processor) allowTargets(targets []autoscan.Target) (allowed, deferred []autoscan.Target) {
+	for _, t := range targets {
+		probe := func() error {
+			start := now()
+			err := t.Available()
+			p.observe(t, "available", start, err)
+			return err
+		}
+
+		b := p.breaker(t.ID())
+		if b.allow(probe) {
+			allowed = append(allowed, t)
+		} else {
+			deferred = append(deferred, t)
+		}
+
+		p.stats.TargetState.Set(int64(b.current()), t.ID())
+	}
+
+	return allowed, deferred
+}
+
+// recordBreaker updates the breaker of the target with the outcome of a scan
+// and logs the state transitions.
+func (p *Processor) recordBreaker(t autoscan.Target, err error) {
+	prev, state := p.breaker(t.ID()).record(err)
+	p.stats.TargetState.Set(int64(state), t.ID())
+
+	switch {
+	case prev == breakerClosed && state == breakerOpen:
+		log.Warn().
+			Err(err).
+			Str("target", t.ID()).
+			Msg("Target Unavailable")
+	case prev != breakerClosed && state == breakerClosed:
+		log.Info().
+			Str("target", t.ID()).
+			Msg("Target Available")
+	}
+}
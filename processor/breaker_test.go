@@ -0,0 +1,176 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/stats"
+)
+
+// unavailableTarget is a mockTarget with a configurable availability.
+type unavailableTarget struct {
+	mockTarget
+	availableErr error
+	checks       int
+}
+
+func (u *unavailableTarget) Available() error {
+	u.checks++
+	return u.availableErr
+}
+
+func TestBreaker(t *testing.T) {
+	start := time.Now()
+	now = func() time.Time { return start }
+	t.Cleanup(func() { now = time.Now })
+
+	var probes int
+	probeErr := fmt.Errorf("offline: %w", autoscan.ErrTargetUnavailable)
+	probe := func() error {
+		probes++
+		return probeErr
+	}
+
+	b := &breaker{}
+	if !b.allow(probe) {
+		t.Fatal("expected closed breaker to allow scans")
+	}
+
+	prev, state := b.record(fmt.Errorf("offline: %w", autoscan.ErrTargetUnavailable))
+	if prev != breakerClosed || state != breakerOpen {
+		t.Fatalf("expected closed -> open, got %s -> %s", prev, state)
+	}
+
+	// Within the cooldown the target is not checked.
+	if b.allow(probe) || !b.blocked() || probes != 0 {
+		t.Fatalf("expected open breaker to block without probing, probes=%d", probes)
+	}
+
+	// After the cooldown the target is checked, but is still unavailable.
+	now = func() time.Time { return start.Add(breakerCooldown) }
+	if b.allow(probe) || probes != 1 {
+		t.Fatalf("expected failed probe to block, probes=%d", probes)
+	}
+	if !b.blocked() {
+		t.Fatal("expected failed probe to restart the cooldown")
+	}
+
+	// The target is available again: a single trial scan is let through.
+	now = func() time.Time { return start.Add(2 * breakerCooldown) }
+	probeErr = nil
+	if !b.allow(probe) || b.current() != breakerHalfOpen {
+		t.Fatalf("expected half-open breaker to allow the trial scan, got %s", b.current())
+	}
+	if b.allow(probe) {
+		t.Fatal("expected half-open breaker to allow a single trial scan")
+	}
+
+	prev, state = b.record(errors.New("timeout"))
+	if prev != breakerHalfOpen || state != breakerClosed {
+		t.Fatalf("expected half-open -> closed, got %s -> %s", prev, state)
+	}
+	if probes != 2 {
+		t.Errorf("expected 2 probes, got %d", probes)
+	}
+}
+
+func TestProcessSkipsUnavailableTarget(t *testing.T) {
+	start := time.Now()
+	now = func() time.Time { return start }
+	t.Cleanup(func() { now = time.Now })
+
+	store := getDatastore(t)
+	p := &Processor{
+		store: store,
+		stats: stats.New(),
+	}
+
+	scans := []autoscan.Scan{
+		{Folder: "/media/movies/Tenet", Time: start.Add(-2 * time.Hour).Unix()},
+		{Folder: "/media/movies/Dune", Time: start.Add(-1 * time.Hour).Unix()},
+	}
+	if err := store.Upsert(scans); err != nil {
+		t.Fatal(err)
+	}
+
+	var plexScans, embyScans []string
+	plex := &mockTarget{id: "plex", scanFn: func(scan autoscan.Scan) error {
+		plexScans = append(plexScans, scan.Folder)
+		return nil
+	}}
+	emby := &unavailableTarget{
+		mockTarget: mockTarget{id: "emby", scanFn: func(scan autoscan.Scan) error {
+			embyScans = append(embyScans, scan.Folder)
+			return fmt.Errorf("offline: %w", autoscan.ErrTargetUnavailable)
+		}},
+		availableErr: fmt.Errorf("offline: %w", autoscan.ErrTargetUnavailable),
+	}
+	targets := []autoscan.Target{plex, emby}
+
+	// Emby goes down on the first scan, plex keeps receiving scans.
+	for range scans {
+		if err := p.Process(targets); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(plexScans) != 2 {
+		t.Errorf("expected plex to receive both scans, got %v", plexScans)
+	}
+	if len(embyScans) != 1 || emby.checks != 0 {
+		t.Errorf("expected emby to be called once and not checked, got %v, %d checks", embyScans, emby.checks)
+	}
+	if got := p.stats.TargetState.Value("emby"); got != int64(breakerOpen) {
+		t.Errorf("expected emby breaker to be open, got %d", got)
+	}
+
+	// Both scans wait for emby without counting an attempt.
+	for _, scan := range scans {
+		delivered, err := store.GetDelivered(scan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !delivered["plex"] || delivered["emby"] {
+			t.Errorf("%s: expected only plex to acknowledge, got %v", scan.Folder, delivered)
+		}
+
+		if attempts, err := store.GetAttempts(scan); err != nil || attempts != 0 {
+			t.Errorf("%s: expected 0 attempts, got %d (%v)", scan.Folder, attempts, err)
+		}
+	}
+
+	// All pending targets are unavailable.
+	if err := p.Process([]autoscan.Target{emby}); !errors.Is(err, autoscan.ErrTargetUnavailable) {
+		t.Fatalf("expected ErrTargetUnavailable, got: %v", err)
+	}
+
+	// Emby is back after the cooldown and receives the postponed scans.
+	now = func() time.Time { return start.Add(breakerCooldown) }
+	emby.availableErr = nil
+	emby.scanFn = func(scan autoscan.Scan) error {
+		embyScans = append(embyScans, scan.Folder)
+		return nil
+	}
+
+	for range scans {
+		if err := p.Process(targets); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(plexScans) != 2 || len(embyScans) != 3 || emby.checks != 1 {
+		t.Errorf("expected emby to be checked once and receive both scans, got plex %v, emby %v, %d checks",
+			plexScans, embyScans, emby.checks)
+	}
+
+	remaining, err := store.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("expected all scans to be removed, got %v", remaining)
+	}
+}
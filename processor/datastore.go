@@ -5,8 +5,10 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/cloudbox/autoscan"
@@ -16,6 +18,10 @@ import (
 
 type datastore struct {
 	db *sqlite.DB
+
+	// coalesce merges the priority and time of queued subfolder scans
+	// into the queued scans of their ancestor folders.
+	coalesce bool
 }
 
 //go:embed migrations
@@ -35,6 +41,8 @@ func newDatastore(db *sqlite.DB) (*datastore, error) {
 	return &datastore{db: db}, nil
 }
 
+// sqlUpsert keeps the failed attempts of a queued scan, so a scan which keeps
+// failing still reaches the maximum amount of attempts while it is triggered again.
 const sqlUpsert = `
 INSERT INTO scan (folder, relative_path, priority, time)
 VALUES (?, ?, ?, ?)
@@ -44,11 +52,55 @@ ON CONFLICT (folder) DO UPDATE SET
 	time = excluded.time
 `
 
+const sqlInsertPath = `
+INSERT OR IGNORE INTO scan_path (folder, relative_path)
+VALUES (?, ?)
+`
+
+// sqlRelativePaths selects the relative paths queued for a scan as a JSON array.
+const sqlRelativePaths = `(
+	SELECT json_group_array(relative_path) FROM (
+		SELECT relative_path FROM scan_path
+		WHERE scan_path.folder = scan.folder
+		ORDER BY relative_path
+	)
+)`
+
 func (*datastore) execUpsert(tx *sql.Tx, scan autoscan.Scan) error {
 	_, err := tx.ExecContext(context.Background(), sqlUpsert, scan.Folder, scan.RelativePath, scan.Priority, scan.Time)
 	if err != nil {
 		return fmt.Errorf("exec upsert: %w", err)
 	}
+
+	paths := scan.RelativePaths
+	if len(paths) == 0 {
+		paths = []string{scan.RelativePath}
+	}
+
+	for _, path := range paths {
+		_, err = tx.ExecContext(context.Background(), sqlInsertPath, scan.Folder, path)
+		if err != nil {
+			return fmt.Errorf("exec insert path: %w", err)
+		}
+	}
+	return nil
+}
+
+// setRelativePaths decodes the JSON array of relative paths into the scan.
+// A scan of the folder as a whole supersedes the scans of individual paths.
+func setRelativePaths(scan *autoscan.Scan, encoded string) error {
+	var paths []string
+	if err := json.Unmarshal([]byte(encoded), &paths); err != nil {
+		return fmt.Errorf("decode relative paths: %w", err)
+	}
+
+	if slices.Contains(paths, "") {
+		scan.RelativePath = ""
+		scan.RelativePaths = nil
+		return nil
+	}
+
+	scan.RelativePaths = paths
 	return nil
 }
 
@@ -78,6 +130,13 @@ func (store *datastore) Upsert(scans []autoscan.Scan) error {
 		if err != nil {
 			return err // defer will handle rollback
 		}
+
+		if store.coalesce {
+			err = store.execCoalesce(tx, scan)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -86,6 +145,50 @@ func (store *datastore) Upsert(scans []autoscan.Scan) error {
 	return nil
 }
 
+// sqlWholeFolder matches the scans of a folder as a whole, which are the only
+// scans covering the subfolders of the folder.
+const sqlWholeFolder = `EXISTS (
+	SELECT 1 FROM scan_path
+	WHERE scan_path.folder = scan.folder AND scan_path.relative_path = ''
+)`
+
+// sqlCoalesceAncestors merges a scan into the queued scans of its ancestor folders.
+const sqlCoalesceAncestors = `
+UPDATE scan SET
+	priority = MAX(priority, ?),
+	time = MAX(time, ?)
+WHERE substr(?, 1, length(folder) + 1) = folder || '/' AND ` + sqlWholeFolder
+
+// sqlCoalesceDescendants merges the queued scans of descendant folders into a scan.
+const sqlCoalesceDescendants = `
+UPDATE scan SET
+	priority = MAX(priority, IFNULL((
+		SELECT MAX(d.priority) FROM scan d
+		WHERE substr(d.folder, 1, length(?) + 1) = ? || '/'
+	), priority)),
+	time = MAX(time, IFNULL((
+		SELECT MAX(d.time) FROM scan d
+		WHERE substr(d.folder, 1, length(?) + 1) = ? || '/'
+	), time))
+WHERE folder = ? AND ` + sqlWholeFolder
+
+// execCoalesce keeps the highest priority and the latest time of a scan and
+// its queued descendants on the ancestor, so the ancestor is not processed
+// before the changes within its subfolders.
+func (*datastore) execCoalesce(tx *sql.Tx, scan autoscan.Scan) error {
+	_, err := tx.ExecContext(context.Background(), sqlCoalesceAncestors, scan.Priority, scan.Time, scan.Folder)
+	if err != nil {
+		return fmt.Errorf("coalesce ancestors: %w", err)
+	}
+
+	_, err = tx.ExecContext(context.Background(), sqlCoalesceDescendants,
+		scan.Folder, scan.Folder, scan.Folder, scan.Folder, scan.Folder)
+	if err != nil {
+		return fmt.Errorf("coalesce descendants: %w", err)
+	}
+	return nil
+}
+
 const sqlGetScansRemaining = `SELECT COUNT(folder) FROM scan`
 
 func (store *datastore) GetScansRemaining() (int, error) {
@@ -104,18 +207,28 @@ func (store *datastore) GetScansRemaining() (int, error) {
 }
 
 const sqlGetAvailableScan = `
-SELECT folder, relative_path, priority, time FROM scan
-WHERE time < ?
-ORDER BY priority DESC, time ASC
+SELECT folder, relative_path, priority, time, ` + sqlRelativePaths + ` FROM scan
+WHERE (forced = 1 OR (time < ? AND next_attempt <= ?))
+	AND folder NOT IN (SELECT value FROM json_each(?))
+ORDER BY forced DESC, priority DESC, time ASC
 LIMIT 1
 `
 
-func (store *datastore) GetAvailableScan(minAge time.Duration) (autoscan.Scan, error) {
-	cutoff := now().Add(-1 * minAge).Unix()
-	row := store.db.RO().QueryRowContext(context.Background(), sqlGetAvailableScan, cutoff)
+// GetAvailableScan returns the next scan to process, skipping the excluded folders.
+func (store *datastore) GetAvailableScan(minAge time.Duration, exclude ...string) (autoscan.Scan, error) {
+	excluded, err := json.Marshal(append([]string{}, exclude...))
+	if err != nil {
+		return autoscan.Scan{}, fmt.Errorf("encode excluded folders: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	current := now()
+	cutoff := current.Add(-1 * minAge).Unix()
+	row := store.db.RO().QueryRowContext(context.Background(), sqlGetAvailableScan,
+		cutoff, current.Unix(), string(excluded))
 
 	scan := autoscan.Scan{}
-	err := row.Scan(&scan.Folder, &scan.RelativePath, &scan.Priority, &scan.Time)
+	paths := ""
+	err = row.Scan(&scan.Folder, &scan.RelativePath, &scan.Priority, &scan.Time, &paths)
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
 		return scan, autoscan.ErrNoScans
@@ -123,11 +236,15 @@ func (store *datastore) GetAvailableScan(minAge time.Duration) (autoscan.Scan, e
 		return scan, fmt.Errorf("get matching: %w: %w", err, autoscan.ErrFatal)
 	}
 
+	if err := setRelativePaths(&scan, paths); err != nil {
+		return scan, fmt.Errorf("get matching: %w: %w", err, autoscan.ErrFatal)
+	}
+
 	return scan, nil
 }
 
 const sqlGetAll = `
-SELECT folder, relative_path, priority, time FROM scan
+SELECT folder, relative_path, priority, time, ` + sqlRelativePaths + ` FROM scan
 `
 
 func (store *datastore) GetAll() ([]autoscan.Scan, error) {
@@ -142,10 +259,15 @@ func (store *datastore) GetAll() ([]autoscan.Scan, error) {
 	var scans []autoscan.Scan
 	for rows.Next() {
 		scan := autoscan.Scan{}
-		if err := rows.Scan(&scan.Folder, &scan.RelativePath, &scan.Priority, &scan.Time); err != nil {
+		paths := ""
+		if err := rows.Scan(&scan.Folder, &scan.RelativePath, &scan.Priority, &scan.Time, &paths); err != nil {
 			return nil, fmt.Errorf("scan row: %w", err)
 		}
 
+		if err := setRelativePaths(&scan, paths); err != nil {
+			return nil, err
+		}
+
 		scans = append(scans, scan)
 	}
 
@@ -155,6 +277,137 @@ func (store *datastore) GetAll() ([]autoscan.Scan, error) {
 	return scans, nil
 }
 
+const sqlGetDelivered = `
+SELECT target FROM scan_delivery
+WHERE folder = ? AND time = ?
+`
+
+// GetDelivered returns the IDs of the targets which have acknowledged
+// the given version of the scan.
+func (store *datastore) GetDelivered(scan autoscan.Scan) (map[string]bool, error) {
+	rows, err := store.db.RO().QueryContext(context.Background(), sqlGetDelivered, scan.Folder, scan.Time)
+	if err != nil {
+		return nil, fmt.Errorf("query delivered: %w: %w", err, autoscan.ErrFatal)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	delivered := make(map[string]bool)
+	for rows.Next() {
+		var target string
+		if err := rows.Scan(&target); err != nil {
+			return nil, fmt.Errorf("scan row: %w: %w", err, autoscan.ErrFatal)
+		}
+
+		delivered[target] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w: %w", err, autoscan.ErrFatal)
+	}
+	return delivered, nil
+}
+
+// sqlMarkDelivered skips scans which are no longer queued.
+const sqlMarkDelivered = `
+INSERT INTO scan_delivery (folder, target, time)
+SELECT folder, ?, ? FROM scan WHERE folder = ?
+ON CONFLICT (folder, target) DO UPDATE SET
+	time = excluded.time
+`
+
+// MarkDelivered records that the given targets have acknowledged the scan.
+// Scans which are no longer queued have been handled already.
+func (store *datastore) MarkDelivered(scan autoscan.Scan, targets []string) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	tx, err := store.db.RW().BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, target := range targets {
+		_, err = tx.ExecContext(context.Background(), sqlMarkDelivered, target, scan.Time, scan.Folder)
+		if err != nil {
+			return fmt.Errorf("mark delivered: %w: %w", err, autoscan.ErrFatal)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+const sqlHasQueuedAncestor = `
+SELECT EXISTS (
+	SELECT 1 FROM scan
+	WHERE substr(?, 1, length(folder) + 1) = folder || '/' AND ` + sqlWholeFolder + `
+)
+`
+
+// HasQueuedAncestor reports whether a scan of an ancestor of the folder as a whole is queued.
+func (store *datastore) HasQueuedAncestor(folder string) (bool, error) {
+	row := store.db.RO().QueryRowContext(context.Background(), sqlHasQueuedAncestor, folder)
+
+	exists := false
+	if err := row.Scan(&exists); err != nil {
+		return false, fmt.Errorf("has queued ancestor: %w: %w", err, autoscan.ErrFatal)
+	}
+	return exists, nil
+}
+
+const sqlMarkDescendantsDelivered = `
+INSERT INTO scan_delivery (folder, target, time)
+SELECT folder, ?, time FROM scan
+WHERE substr(folder, 1, length(?) + 1) = ? || '/' AND time <= ?
+	AND EXISTS (SELECT 1 FROM scan_path WHERE folder = ? AND relative_path = '')
+ON CONFLICT (folder, target) DO UPDATE SET
+	time = excluded.time
+`
+
+// MarkDescendantsDelivered records that the given targets have acknowledged
+// the queued scans of all descendants of the scan's folder, as far as these
+// did not change after the scan and the folder was scanned as a whole.
+func (store *datastore) MarkDescendantsDelivered(scan autoscan.Scan, targets []string) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	tx, err := store.db.RW().BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, target := range targets {
+		_, err = tx.ExecContext(context.Background(), sqlMarkDescendantsDelivered,
+			target, scan.Folder, scan.Folder, scan.Time, scan.Folder)
+		if err != nil {
+			return fmt.Errorf("mark descendants delivered: %w: %w", err, autoscan.ErrFatal)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
 const sqlDelete = `
 DELETE FROM scan WHERE folder=?
 `
@@ -168,4 +421,339 @@ func (store *datastore) Delete(scan autoscan.Scan) error {
 	return nil
 }
 
+// sqlScanFilter matches scans by folder prefix and minimum priority.
+const sqlScanFilter = `
+WHERE substr(folder, 1, length(?)) = ? AND priority >= ?
+`
+
+const sqlCountQueued = `SELECT COUNT(folder) FROM scan` + sqlScanFilter
+
+const sqlListQueued = `
+SELECT folder, relative_path, priority, time, attempts, last_error, next_attempt, forced,
+	` + sqlRelativePaths + ` FROM scan
+` + sqlScanFilter + `
+ORDER BY forced DESC, priority DESC, time ASC
+LIMIT ? OFFSET ?
+`
+
+// ListQueued returns one page of queued scans matching the options,
+// in processing order, together with the total amount of matching scans.
+func (store *datastore) ListQueued(opts ListOptions) ([]QueuedScan, int, error) {
+	total := 0
+	row := store.db.RO().QueryRowContext(context.Background(), sqlCountQueued,
+		opts.Prefix, opts.Prefix, opts.MinPriority)
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count queued scans: %w", err)
+	}
+
+	rows, err := store.db.RO().QueryContext(context.Background(), sqlListQueued,
+		opts.Prefix, opts.Prefix, opts.MinPriority, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query queued scans: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	scans := make([]QueuedScan, 0)
+	for rows.Next() {
+		scan := QueuedScan{}
+		paths := ""
+		err := rows.Scan(&scan.Folder, &scan.RelativePath, &scan.Priority, &scan.Time,
+			&scan.Attempts, &scan.LastError, &scan.NextAttempt, &scan.Forced, &paths)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan row: %w", err)
+		}
+
+		if err := setRelativePaths(&scan.Scan, paths); err != nil {
+			return nil, 0, err
+		}
+
+		scans = append(scans, scan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+	return scans, total, nil
+}
+
+const sqlGetQueued = `
+SELECT folder, relative_path, priority, time, attempts, last_error, next_attempt, forced,
+	` + sqlRelativePaths + ` FROM scan
+WHERE folder = ?
+`
+
+// GetQueued returns the queued scan for the folder.
+func (store *datastore) GetQueued(folder string) (QueuedScan, error) {
+	row := store.db.RO().QueryRowContext(context.Background(), sqlGetQueued, folder)
+
+	scan := QueuedScan{}
+	paths := ""
+	err := row.Scan(&scan.Folder, &scan.RelativePath, &scan.Priority, &scan.Time,
+		&scan.Attempts, &scan.LastError, &scan.NextAttempt, &scan.Forced, &paths)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return scan, fmt.Errorf("%v: %w", folder, ErrScanNotFound)
+	case err != nil:
+		return scan, fmt.Errorf("get queued scan: %w", err)
+	}
+
+	if err := setRelativePaths(&scan.Scan, paths); err != nil {
+		return scan, err
+	}
+
+	return scan, nil
+}
+
+// execFolder executes a statement against a single queued folder
+// and returns ErrScanNotFound when no scan was affected.
+func (store *datastore) execFolder(query, folder string, args ...any) error {
+	res, err := store.db.RW().ExecContext(context.Background(), query, append(args, folder)...)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%v: %w", folder, ErrScanNotFound)
+	}
+
+	return nil
+}
+
+// Remove deletes the queued scan for the folder.
+func (store *datastore) Remove(folder string) error {
+	return store.execFolder(sqlDelete, folder)
+}
+
+const sqlSetPriority = `
+UPDATE scan SET priority = ? WHERE folder = ?
+`
+
+// SetPriority changes the priority of the queued scan for the folder.
+func (store *datastore) SetPriority(folder string, priority int) error {
+	return store.execFolder(sqlSetPriority, folder, priority)
+}
+
+const sqlForce = `
+UPDATE scan SET forced = 1, next_attempt = 0 WHERE folder = ?
+`
+
+// Force makes the queued scan for the folder available immediately,
+// ahead of all other scans, regardless of its age and retry backoff.
+func (store *datastore) Force(folder string) error {
+	return store.execFolder(sqlForce, folder)
+}
+
+const sqlGetAttempts = `
+SELECT attempts FROM scan WHERE folder = ?
+`
+
+// GetAttempts returns the amount of failed delivery attempts of the scan.
+// Returns ErrScanNotFound when the scan is no longer queued.
+func (store *datastore) GetAttempts(scan autoscan.Scan) (int, error) {
+	row := store.db.RO().QueryRowContext(context.Background(), sqlGetAttempts, scan.Folder)
+
+	attempts := 0
+	err := row.Scan(&attempts)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return attempts, fmt.Errorf("%v: %w", scan.Folder, ErrScanNotFound)
+	case err != nil:
+		return attempts, fmt.Errorf("get attempts: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	return attempts, nil
+}
+
+const sqlRetry = `
+UPDATE scan SET
+	attempts = ?,
+	last_error = ?,
+	next_attempt = ?,
+	forced = 0
+WHERE folder = ?
+`
+
+// Retry records a failed delivery attempt and postpones the scan until nextAttempt.
+func (store *datastore) Retry(scan autoscan.Scan, attempts int, lastError string, nextAttempt time.Time) error {
+	_, err := store.db.RW().ExecContext(context.Background(), sqlRetry,
+		attempts, lastError, nextAttempt.Unix(), scan.Folder)
+	if err != nil {
+		return fmt.Errorf("retry: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	return nil
+}
+
+const sqlPostpone = `
+UPDATE scan SET
+	next_attempt = ?,
+	forced = 0
+WHERE folder = ?
+`
+
+// Postpone delays the scan until nextAttempt without counting a failed attempt.
+// Scans which are no longer queued are skipped.
+func (store *datastore) Postpone(scan autoscan.Scan, nextAttempt time.Time) error {
+	_, err := store.db.RW().ExecContext(context.Background(), sqlPostpone, nextAttempt.Unix(), scan.Folder)
+	if err != nil {
+		return fmt.Errorf("postpone: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	return nil
+}
+
+const sqlInsertFailed = `
+INSERT INTO scan_failed (folder, relative_path, relative_paths, priority, time, attempts, last_error, failed_at)
+SELECT folder, relative_path, ` + sqlRelativePaths + `, priority, time, ?, ?, ? FROM scan
+WHERE folder = ?
+ON CONFLICT (folder) DO UPDATE SET
+	relative_path = excluded.relative_path,
+	relative_paths = excluded.relative_paths,
+	priority = excluded.priority,
+	time = excluded.time,
+	attempts = excluded.attempts,
+	last_error = excluded.last_error,
+	failed_at = excluded.failed_at
+`
+
+// MoveToFailed moves the scan from the queue to the dead-letter table.
+func (store *datastore) MoveToFailed(scan autoscan.Scan, attempts int, lastError string) error {
+	tx, err := store.db.RW().BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	_, err = tx.ExecContext(context.Background(), sqlInsertFailed,
+		attempts, lastError, now().Unix(), scan.Folder)
+	if err != nil {
+		return fmt.Errorf("insert failed: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	_, err = tx.ExecContext(context.Background(), sqlDelete, scan.Folder)
+	if err != nil {
+		return fmt.Errorf("delete: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+const sqlGetFailed = `
+SELECT folder, relative_path, relative_paths, priority, time, attempts, last_error, failed_at FROM scan_failed
+ORDER BY failed_at DESC
+`
+
+// GetFailed returns all scans in the dead-letter table, most recent failures first.
+func (store *datastore) GetFailed() ([]FailedScan, error) {
+	rows, err := store.db.RO().QueryContext(context.Background(), sqlGetFailed)
+	if err != nil {
+		return nil, fmt.Errorf("query failed scans: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	scans := make([]FailedScan, 0)
+	for rows.Next() {
+		scan := FailedScan{}
+		paths := ""
+		err := rows.Scan(&scan.Folder, &scan.RelativePath, &paths, &scan.Priority, &scan.Time,
+			&scan.Attempts, &scan.LastError, &scan.FailedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		if err := setRelativePaths(&scan.Scan, paths); err != nil {
+			return nil, err
+		}
+
+		scans = append(scans, scan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return scans, nil
+}
+
+const sqlGetFailedScan = `
+SELECT folder, relative_path, relative_paths, priority, time FROM scan_failed
+WHERE folder = ?
+`
+
+const sqlDeleteFailed = `
+DELETE FROM scan_failed WHERE folder=?
+`
+
+const sqlResetAttempts = `
+UPDATE scan SET
+	attempts = 0,
+	last_error = '',
+	next_attempt = 0
+WHERE folder = ?
+`
+
+// Requeue moves a scan from the dead-letter table back into the queue,
+// with its attempt counter reset.
+func (store *datastore) Requeue(folder string) error {
+	tx, err := store.db.RW().BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	scan := autoscan.Scan{}
+	paths := ""
+	row := tx.QueryRowContext(context.Background(), sqlGetFailedScan, folder)
+	err = row.Scan(&scan.Folder, &scan.RelativePath, &paths, &scan.Priority, &scan.Time)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%v: %w", folder, ErrScanNotFound)
+	case err != nil:
+		return fmt.Errorf("get failed scan: %w", err)
+	}
+
+	if err = setRelativePaths(&scan, paths); err != nil {
+		return err
+	}
+
+	if err = store.execUpsert(tx, scan); err != nil {
+		return err
+	}
+
+	// the folder might have been queued again in the meantime
+	if _, err = tx.ExecContext(context.Background(), sqlResetAttempts, folder); err != nil {
+		return fmt.Errorf("reset attempts: %w", err)
+	}
+
+	if _, err = tx.ExecContext(context.Background(), sqlDeleteFailed, folder); err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
 var now = time.Now
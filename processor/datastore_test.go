@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -353,3 +354,516 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestDelivered(t *testing.T) {
+	store := getDatastore(t)
+
+	scan := autoscan.Scan{Folder: "1", Time: 10}
+	if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.MarkDelivered(scan, []string{"plex", "emby"}); err != nil {
+		t.Fatal(err)
+	}
+
+	delivered, err := store.GetDelivered(scan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{"plex": true, "emby": true}
+	if !reflect.DeepEqual(delivered, want) {
+		t.Errorf("Delivered do not match: %v vs %v", delivered, want)
+	}
+
+	// A newer version of the scan must be delivered to every target again.
+	newer := autoscan.Scan{Folder: "1", Time: 20}
+	if err := store.Upsert([]autoscan.Scan{newer}); err != nil {
+		t.Fatal(err)
+	}
+
+	delivered, err = store.GetDelivered(newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(delivered) != 0 {
+		t.Errorf("Expected no deliveries for newer scan, got %v", delivered)
+	}
+
+	// Deleting the scan removes its deliveries.
+	if err := store.Delete(newer); err != nil {
+		t.Fatal(err)
+	}
+
+	delivered, err = store.GetDelivered(scan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(delivered) != 0 {
+		t.Errorf("Expected deliveries to be removed with the scan, got %v", delivered)
+	}
+}
+
+func TestRelativePaths(t *testing.T) {
+	now = time.Now
+	store := getDatastore(t)
+
+	scans := []autoscan.Scan{
+		{Folder: "/tv/Westworld/Season 1", RelativePath: "s01e02.mkv", Time: 10},
+		{Folder: "/tv/Westworld/Season 1", RelativePath: "s01e01.mkv", Time: 20},
+		{Folder: "/tv/Westworld/Season 1", RelativePath: "s01e02.mkv", Time: 30},
+		{Folder: "/tv/Westworld/Season 1", RelativePath: "s01e03.mkv", Time: 40},
+	}
+	if err := store.Upsert(scans); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, err := store.GetAvailableScan(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := autoscan.Scan{
+		Folder:        "/tv/Westworld/Season 1",
+		RelativePath:  "s01e03.mkv",
+		RelativePaths: []string{"s01e01.mkv", "s01e02.mkv", "s01e03.mkv"},
+		Time:          40,
+	}
+	if !reflect.DeepEqual(scan, want) {
+		t.Errorf("Scans do not match: %v vs %v", scan, want)
+	}
+
+	// The relative paths survive a round-trip through the failed scans.
+	if err := store.MoveToFailed(scan, 1, "failed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Requeue(scan.Folder); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, err = store.GetAvailableScan(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(scan, want) {
+		t.Errorf("Requeued scans do not match: %v vs %v", scan, want)
+	}
+
+	// A scan of the folder as a whole supersedes the individual paths.
+	if err := store.Upsert([]autoscan.Scan{{Folder: "/tv/Westworld/Season 1", Time: 50}}); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, err = store.GetAvailableScan(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = autoscan.Scan{Folder: "/tv/Westworld/Season 1", Time: 50}
+	if !reflect.DeepEqual(scan, want) {
+		t.Errorf("Scans do not match: %v vs %v", scan, want)
+	}
+
+	// Processing the scan removes its relative paths.
+	if err := store.Delete(scan); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Upsert([]autoscan.Scan{{Folder: "/tv/Westworld/Season 1", RelativePath: "s01e04.mkv", Time: 60}}); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, err = store.GetAvailableScan(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(scan.RelativePaths, []string{"s01e04.mkv"}) {
+		t.Errorf("Expected only the new relative path, got %v", scan.RelativePaths)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	type Test struct {
+		Name     string
+		Coalesce bool
+		Scans    []autoscan.Scan
+		Want     []autoscan.Scan
+	}
+
+	testCases := []Test{
+		{
+			Name:     "Descendant merged into ancestor",
+			Coalesce: true,
+			Scans: []autoscan.Scan{
+				{Folder: "/tv/Show", Priority: 0, Time: 100},
+				{Folder: "/tv/Show/Season 1", Priority: 5, Time: 200},
+				{Folder: "/tv/Showcase", Priority: 9, Time: 300},
+			},
+			Want: []autoscan.Scan{
+				{Folder: "/tv/Show", Priority: 5, Time: 200},
+				{Folder: "/tv/Show/Season 1", Priority: 5, Time: 200},
+				{Folder: "/tv/Showcase", Priority: 9, Time: 300},
+			},
+		},
+		{
+			Name:     "Ancestor takes over descendants",
+			Coalesce: true,
+			Scans: []autoscan.Scan{
+				{Folder: "/tv/Show/Season 1", Priority: 5, Time: 200},
+				{Folder: "/tv/Other", Priority: 2, Time: 300},
+				{Folder: "/tv", Priority: 0, Time: 100},
+			},
+			Want: []autoscan.Scan{
+				{Folder: "/tv", Priority: 5, Time: 300},
+				{Folder: "/tv/Other", Priority: 2, Time: 300},
+				{Folder: "/tv/Show/Season 1", Priority: 5, Time: 200},
+			},
+		},
+		{
+			Name:     "Ancestor of a single file",
+			Coalesce: true,
+			Scans: []autoscan.Scan{
+				{Folder: "/tv/Show", RelativePath: "tvshow.nfo", Priority: 0, Time: 100},
+				{Folder: "/tv/Show/Season 1", Priority: 5, Time: 200},
+			},
+			Want: []autoscan.Scan{
+				{Folder: "/tv/Show", Priority: 0, Time: 100},
+				{Folder: "/tv/Show/Season 1", Priority: 5, Time: 200},
+			},
+		},
+		{
+			Name:     "Disabled",
+			Coalesce: false,
+			Scans: []autoscan.Scan{
+				{Folder: "/tv/Show", Priority: 0, Time: 100},
+				{Folder: "/tv/Show/Season 1", Priority: 5, Time: 200},
+			},
+			Want: []autoscan.Scan{
+				{Folder: "/tv/Show", Priority: 0, Time: 100},
+				{Folder: "/tv/Show/Season 1", Priority: 5, Time: 200},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			store := getDatastore(t)
+			store.coalesce = tc.Coalesce
+
+			for _, scan := range tc.Scans {
+				if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, want := range tc.Want {
+				scan, err := store.GetScan(want.Folder)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !reflect.DeepEqual(scan, want) {
+					t.Errorf("Scans do not match: %v vs %v", scan, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkDescendantsDelivered(t *testing.T) {
+	store := getDatastore(t)
+
+	scans := []autoscan.Scan{
+		{Folder: "/tv/Show", Time: 200},
+		{Folder: "/tv/Show/Season 1", Time: 100},
+		{Folder: "/tv/Show/Season 2", Time: 300},
+		{Folder: "/tv/Showcase", Time: 100},
+	}
+	if err := store.Upsert(scans); err != nil {
+		t.Fatal(err)
+	}
+
+	queued, err := store.HasQueuedAncestor("/tv/Show/Season 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !queued {
+		t.Error("Expected a queued ancestor for /tv/Show/Season 1")
+	}
+
+	queued, err = store.HasQueuedAncestor("/tv/Showcase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queued {
+		t.Error("Expected no queued ancestor for /tv/Showcase")
+	}
+
+	if err := store.MarkDescendantsDelivered(scans[0], []string{"plex"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the descendants which did not change after the scan are delivered.
+	for _, tc := range []struct {
+		scan autoscan.Scan
+		want bool
+	}{
+		{scans[0], false},
+		{scans[1], true},
+		{scans[2], false},
+		{scans[3], false},
+	} {
+		delivered, err := store.GetDelivered(tc.scan)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if delivered["plex"] != tc.want {
+			t.Errorf("%s: expected delivered=%v, got %v", tc.scan.Folder, tc.want, delivered["plex"])
+		}
+	}
+}
+
+func TestRetryAndRequeue(t *testing.T) {
+	testTime := time.Now().UTC()
+	now = func() time.Time {
+		return testTime
+	}
+
+	store := getDatastore(t)
+
+	scan := autoscan.Scan{Folder: "1", Priority: 3, Time: testTime.Add(-1 * time.Hour).Unix()}
+	if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A scan scheduled for a later retry is not available yet.
+	if err := store.Retry(scan, 1, "bad request", testTime.Add(1*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.GetAvailableScan(0); !errors.Is(err, autoscan.ErrNoScans) {
+		t.Fatalf("Expected ErrNoScans, got: %v", err)
+	}
+
+	attempts, err := store.GetAttempts(scan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+
+	// Triggering the scan again keeps its attempts and retry backoff.
+	if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.GetAvailableScan(0); !errors.Is(err, autoscan.ErrNoScans) {
+		t.Fatalf("Expected ErrNoScans after upsert, got: %v", err)
+	}
+
+	attempts, err = store.GetAttempts(scan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt after upsert, got %d", attempts)
+	}
+
+	// Moving the scan to the dead-letter table removes it from the queue.
+	if err := store.MoveToFailed(scan, 2, "bad request"); err != nil {
+		t.Fatal(err)
+	}
+
+	remaining, err := store.GetScansRemaining()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remaining != 0 {
+		t.Errorf("Expected 0 scans remaining, got %d", remaining)
+	}
+
+	failed, err := store.GetFailed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantFailed := []FailedScan{{
+		Scan:      scan,
+		Attempts:  2,
+		LastError: "bad request",
+		FailedAt:  testTime.Unix(),
+	}}
+
+	if !reflect.DeepEqual(failed, wantFailed) {
+		t.Log(failed)
+		t.Error("Failed scans do not match")
+	}
+
+	// Requeueing moves the scan back with a reset attempt counter.
+	if err := store.Requeue(scan.Folder); err != nil {
+		t.Fatal(err)
+	}
+
+	available, err := store.GetAvailableScan(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(available, scan) {
+		t.Log(available)
+		t.Error("Requeued scan does not match")
+	}
+
+	attempts, err = store.GetAttempts(scan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 0 {
+		t.Errorf("Expected attempts to be reset, got %d", attempts)
+	}
+
+	if err := store.Requeue(scan.Folder); !errors.Is(err, ErrScanNotFound) {
+		t.Errorf("Expected ErrScanNotFound, got: %v", err)
+	}
+}
+
+func TestListQueued(t *testing.T) {
+	store := getDatastore(t)
+
+	err := store.Upsert([]autoscan.Scan{
+		{Folder: "/tv/Westworld", Priority: 1, Time: 1},
+		{Folder: "/tv/Severance", Priority: 5, Time: 2},
+		{Folder: "/movies/Tenet", Priority: 3, Time: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Test struct {
+		Name        string
+		Options     ListOptions
+		WantFolders []string
+		WantTotal   int
+	}
+
+	testCases := []Test{
+		{
+			Name:        "Returns all scans in processing order",
+			Options:     ListOptions{MinPriority: math.MinInt, Limit: 10},
+			WantFolders: []string{"/tv/Severance", "/movies/Tenet", "/tv/Westworld"},
+			WantTotal:   3,
+		},
+		{
+			Name:        "Filters by folder prefix",
+			Options:     ListOptions{Prefix: "/tv/", MinPriority: math.MinInt, Limit: 10},
+			WantFolders: []string{"/tv/Severance", "/tv/Westworld"},
+			WantTotal:   2,
+		},
+		{
+			Name:        "Filters by minimum priority",
+			Options:     ListOptions{MinPriority: 3, Limit: 10},
+			WantFolders: []string{"/tv/Severance", "/movies/Tenet"},
+			WantTotal:   2,
+		},
+		{
+			Name:        "Pages with limit and offset",
+			Options:     ListOptions{MinPriority: math.MinInt, Limit: 1, Offset: 1},
+			WantFolders: []string{"/movies/Tenet"},
+			WantTotal:   3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			scans, total, err := store.ListQueued(tc.Options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			folders := make([]string, 0, len(scans))
+			for _, scan := range scans {
+				folders = append(folders, scan.Folder)
+			}
+
+			if !reflect.DeepEqual(folders, tc.WantFolders) {
+				t.Errorf("Folders do not match: %v vs %v", folders, tc.WantFolders)
+			}
+
+			if total != tc.WantTotal {
+				t.Errorf("Totals do not match: %d vs %d", total, tc.WantTotal)
+			}
+		})
+	}
+}
+
+func TestManageQueued(t *testing.T) {
+	testTime := time.Now().UTC()
+	now = func() time.Time {
+		return testTime
+	}
+
+	store := getDatastore(t)
+
+	err := store.Upsert([]autoscan.Scan{
+		{Folder: "old", Priority: 1, Time: testTime.Add(-1 * time.Hour).Unix()},
+		{Folder: "young", Priority: 0, Time: testTime.Unix()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Forcing makes a young scan available ahead of the others.
+	if err := store.Force("young"); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, err := store.GetAvailableScan(10 * time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scan.Folder != "young" {
+		t.Errorf("Expected forced scan, got %v", scan.Folder)
+	}
+
+	if err := store.SetPriority("old", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	queued, err := store.GetQueued("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if queued.Priority != 10 {
+		t.Errorf("Expected priority 10, got %d", queued.Priority)
+	}
+
+	if err := store.Remove("old"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, err := range []error{
+		store.Remove("old"),
+		store.SetPriority("old", 1),
+		store.Force("old"),
+	} {
+		if !errors.Is(err, ErrScanNotFound) {
+			t.Errorf("Expected ErrScanNotFound, got: %v", err)
+		}
+	}
+
+	if _, err := store.GetQueued("old"); !errors.Is(err, ErrScanNotFound) {
+		t.Errorf("Expected ErrScanNotFound, got: %v", err)
+	}
+}
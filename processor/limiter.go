@@ -0,0 +1,94 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// targetLimiter restricts the concurrency and request rate towards a single target.
+type targetLimiter struct {
+	rl  *rate.Limiter
+	sem *semaphore.Weighted // nil when the amount of scans in flight is unlimited
+}
+
+// newTargetLimiter creates a limiter for the given limits. Without a rate limit,
+// scans are sent at most once per scanDelay, or unlimited when scanDelay is zero.
+func newTargetLimiter(limits autoscan.TargetLimits, scanDelay time.Duration) *targetLimiter {
+	limit := rate.Inf
+	switch {
+	case limits.RateLimit > 0:
+		limit = rate.Limit(limits.RateLimit)
+	case scanDelay > 0:
+		limit = rate.Every(scanDelay)
+	}
+
+	l := &targetLimiter{
+		rl: rate.NewLimiter(limit, 1),
+	}
+
+	if limits.MaxInFlight > 0 {
+		l.sem = semaphore.NewWeighted(int64(limits.MaxInFlight))
+	}
+
+	return l
+}
+
+// Acquire blocks until a scan may be sent to the target.
+// The returned function must be called once the scan has been sent.
+func (l *targetLimiter) Acquire(ctx context.Context) (func(), error) {
+	if l.sem != nil {
+		if err := l.sem.Acquire(ctx, 1); err != nil {
+			return nil, fmt.Errorf("acquire semaphore: %w", err)
+		}
+	}
+
+	release := func() {
+		if l.sem != nil {
+			l.sem.Release(1)
+		}
+	}
+
+	if err := l.rl.Wait(ctx); err != nil {
+		release()
+		return nil, fmt.Errorf("wait for rate limit: %w", err)
+	}
+
+	return release, nil
+}
+
+// Limit sets the limits of the target with the given ID.
+// Targets without limits are sent at most one scan per scan delay.
+func (p *Processor) Limit(targetID string, limits autoscan.TargetLimits) {
+	p.limitersMu.Lock()
+	defer p.limitersMu.Unlock()
+
+	if p.limiters == nil {
+		p.limiters = make(map[string]*targetLimiter)
+	}
+
+	p.limiters[targetID] = newTargetLimiter(limits, p.scanDelay)
+}
+
+// limiter returns the limiter of the target, creating a default one when needed.
+func (p *Processor) limiter(targetID string) *targetLimiter {
+	p.limitersMu.Lock()
+	defer p.limitersMu.Unlock()
+
+	if p.limiters == nil {
+		p.limiters = make(map[string]*targetLimiter)
+	}
+
+	l, ok := p.limiters[targetID]
+	if !ok {
+		l = newTargetLimiter(autoscan.TargetLimits{}, p.scanDelay)
+		p.limiters[targetID] = l
+	}
+
+	return l
+}
@@ -0,0 +1,142 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/stats"
+)
+
+func TestTargetLimiterMaxInFlight(t *testing.T) {
+	l := newTargetLimiter(autoscan.TargetLimits{MaxInFlight: 2}, 0)
+
+	var inFlight, peak atomic.Int64
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			release, err := l.Acquire(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+
+			current := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if current <= p || peak.CompareAndSwap(p, current) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+			inFlight.Add(-1)
+		})
+	}
+
+	wg.Wait()
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("expected at most 2 scans in flight, got %d", got)
+	}
+}
+
+func TestTargetLimiterRate(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Limits    autoscan.TargetLimits
+		ScanDelay time.Duration
+		Expected  time.Duration // minimum duration of three acquisitions
+	}{
+		{Name: "Rate limit", Limits: autoscan.TargetLimits{RateLimit: 20}, ScanDelay: time.Hour, Expected: 100 * time.Millisecond},
+		{Name: "Scan delay", ScanDelay: 50 * time.Millisecond, Expected: 100 * time.Millisecond},
+		{Name: "Unlimited", Expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			l := newTargetLimiter(tc.Limits, tc.ScanDelay)
+
+			start := time.Now()
+			for range 3 {
+				release, err := l.Acquire(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				release()
+			}
+
+			elapsed := time.Since(start)
+			if elapsed < tc.Expected {
+				t.Errorf("expected at least %v, got %v", tc.Expected, elapsed)
+			}
+			if tc.Expected == 0 && elapsed > 50*time.Millisecond {
+				t.Errorf("expected no delay, got %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestProcessConcurrentWorkers(t *testing.T) {
+	now = time.Now
+
+	store := getDatastore(t)
+	p := &Processor{
+		store: store,
+		stats: stats.New(),
+	}
+
+	const scanCount = 20
+
+	var scans []autoscan.Scan
+	for i := range scanCount {
+		scans = append(scans, autoscan.Scan{
+			Folder: "/media/tv/" + string(rune('a'+i)),
+			Time:   time.Now().Add(-1 * time.Hour).Unix(),
+		})
+	}
+	if err := store.Upsert(scans); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	received := make(map[string]int)
+	targets := []autoscan.Target{
+		&mockTarget{id: "jellyfin", scanFn: func(scan autoscan.Scan) error {
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			received[scan.Folder]++
+			return nil
+		}},
+	}
+	p.Limit("jellyfin", autoscan.TargetLimits{MaxInFlight: 3})
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for {
+				err := p.Process(targets)
+				if err != nil {
+					return
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+
+	if len(received) != scanCount {
+		t.Errorf("expected %d scans, got %d", scanCount, len(received))
+	}
+	for folder, count := range received {
+		if count != 1 {
+			t.Errorf("expected %s to be scanned once, got %d", folder, count)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+-- Track which targets have acknowledged a scan, so only pending targets are retried.
+-- The time column pins the acknowledgement to the scan version that was delivered:
+-- when the folder is upserted again with a newer time, all targets become pending.
+CREATE TABLE IF NOT EXISTS scan_delivery (
+    "folder" TEXT NOT NULL,
+    "target" TEXT NOT NULL,
+    "time" INTEGER NOT NULL,
+    PRIMARY KEY(folder, target),
+    FOREIGN KEY(folder) REFERENCES scan(folder) ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+-- Track failed delivery attempts, so scans that keep failing are retried with a backoff
+ALTER TABLE scan ADD COLUMN "attempts" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE scan ADD COLUMN "last_error" TEXT NOT NULL DEFAULT '';
+ALTER TABLE scan ADD COLUMN "next_attempt" INTEGER NOT NULL DEFAULT 0;
+
+-- Dead-letter table for scans which exceeded the maximum amount of attempts
+CREATE TABLE IF NOT EXISTS scan_failed (
+    "folder" TEXT NOT NULL,
+    "relative_path" TEXT NOT NULL DEFAULT '',
+    "priority" INTEGER NOT NULL,
+    "time" INTEGER NOT NULL,
+    "attempts" INTEGER NOT NULL,
+    "last_error" TEXT NOT NULL DEFAULT '',
+    "failed_at" INTEGER NOT NULL,
+    PRIMARY KEY(folder)
+);
@@ -0,0 +1,2 @@
+-- Scans forced through the API skip the minimum age and retry backoff
+ALTER TABLE scan ADD COLUMN "forced" INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+-- Keep every relative path queued for a folder, instead of only the most recent one.
+-- An empty relative path requests a scan of the folder as a whole.
+CREATE TABLE IF NOT EXISTS scan_path (
+    "folder" TEXT NOT NULL,
+    "relative_path" TEXT NOT NULL,
+    PRIMARY KEY(folder, relative_path),
+    FOREIGN KEY(folder) REFERENCES scan(folder) ON DELETE CASCADE
+);
+
+INSERT OR IGNORE INTO scan_path (folder, relative_path)
+SELECT folder, relative_path FROM scan;
+
+-- Failed scans keep their relative paths as a JSON array
+ALTER TABLE scan_failed ADD COLUMN "relative_paths" TEXT NOT NULL DEFAULT '[]';
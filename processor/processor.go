@@ -4,12 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
-	"golang.org/x/sync/errgroup"
 
 	"github.com/cloudbox/autoscan"
 	"github.com/cloudbox/autoscan/internal/sqlite"
@@ -22,9 +23,55 @@ type Config struct {
 	MinimumAge time.Duration
 	Stats      *stats.Stats
 
+	// MaxAttempts is the amount of failed delivery attempts after which
+	// a scan is moved to the dead-letter table.
+	MaxAttempts int
+	// RetryDelay is the delay before the first retry of a failed scan.
+	// The delay doubles with every subsequent failed attempt.
+	RetryDelay time.Duration
+
+	// ScanDelay is the minimum delay between two scans sent to the same target,
+	// unless the target has its own rate limit.
+	ScanDelay time.Duration
+
+	// Coalesce merges queued scans of subfolders into a queued scan of their
+	// ancestor folder, for the targets which accept coalesced scans.
+	Coalesce bool
+
 	Db *sqlite.DB
 }
 
+// FailedScan is a scan which exceeded the maximum amount of delivery attempts.
+type FailedScan struct {
+	autoscan.Scan
+	Attempts  int
+	LastError string
+	FailedAt  int64 // Unix timestamp
+}
+
+// QueuedScan is a scan waiting in the queue, along with its delivery state.
+type QueuedScan struct {
+	autoscan.Scan
+	Attempts    int
+	LastError   string
+	NextAttempt int64 // Unix timestamp
+	Forced      bool
+}
+
+// ListOptions filters and pages the queued scans.
+type ListOptions struct {
+	Prefix      string // only scans with a folder starting with Prefix
+	MinPriority int    // only scans with at least this priority
+	Limit       int
+	Offset      int
+}
+
+// ErrScanNotFound is returned when the requested scan does not exist.
+var ErrScanNotFound = errors.New("scan not found")
+
+// ErrScanProcessing is returned when the requested scan is being processed.
+var ErrScanProcessing = errors.New("scan is being processed")
+
 // New creates a Processor from the given Config.
 func New(cfg Config) (*Processor, error) {
 	store, err := newDatastore(cfg.Db)
@@ -32,26 +79,49 @@ func New(cfg Config) (*Processor, error) {
 		return nil, err
 	}
 
+	store.coalesce = cfg.Coalesce
+
 	proc := &Processor{
 		anchors:     cfg.Anchors,
 		minimumAge:  cfg.MinimumAge,
+		maxAttempts: cfg.MaxAttempts,
+		retryDelay:  cfg.RetryDelay,
+		coalesce:    cfg.Coalesce,
+		scanDelay:   cfg.ScanDelay,
 		store:       store,
 		stats:       cfg.Stats,
 		db:          cfg.Db,
 		anchorState: make(map[string]bool),
+		limiters:    make(map[string]*targetLimiter),
+		breakers:    make(map[string]*breaker),
+		claimed:     make(map[string]bool),
 	}
 	return proc, nil
 }
 
 // Processor dequeues scans and dispatches them to media server targets.
+// Process may be called by multiple workers concurrently.
 type Processor struct {
 	anchors     []string
 	anchorState map[string]bool // tracks per-anchor availability for transition logging
+	anchorMu    sync.Mutex
 	minimumAge  time.Duration
+	maxAttempts int
+	retryDelay  time.Duration
+	coalesce    bool
+	scanDelay   time.Duration
 	store       *datastore
 	stats       *stats.Stats
 	db          *sqlite.DB
-	processMu   sync.Mutex // Protects against concurrent Process() calls
+
+	limiters   map[string]*targetLimiter // per target ID
+	limitersMu sync.Mutex
+
+	breakers   map[string]*breaker // per target ID
+	breakersMu sync.Mutex
+
+	claimed map[string]bool // folders of the scans being processed
+	claimMu sync.Mutex
 }
 
 // Add enqueues one or more scans for processing.
@@ -60,11 +130,71 @@ func (p *Processor) Add(scans ...autoscan.Scan) error {
 	return p.store.Upsert(scans)
 }
 
+// AddFrom returns a ProcessorFunc which enqueues scans on behalf of the named
+// trigger, so the received scans are also counted per trigger.
+func (p *Processor) AddFrom(trigger string) autoscan.ProcessorFunc {
+	return func(scans ...autoscan.Scan) error {
+		p.stats.TriggerScans.Add(int64(len(scans)), trigger)
+		return p.Add(scans...)
+	}
+}
+
 // ScansRemaining returns the amount of scans remaining
 func (p *Processor) ScansRemaining() (int, error) {
 	return p.store.GetScansRemaining()
 }
 
+// QueuedScans returns one page of queued scans in processing order,
+// together with the total amount of scans matching the options.
+func (p *Processor) QueuedScans(opts ListOptions) ([]QueuedScan, int, error) {
+	return p.store.ListQueued(opts)
+}
+
+// QueuedScan returns the queued scan for the folder.
+// Returns ErrScanNotFound when no scan is queued for the folder.
+func (p *Processor) QueuedScan(folder string) (QueuedScan, error) {
+	return p.store.GetQueued(folder)
+}
+
+// Remove deletes the queued scan for the folder.
+// Returns ErrScanNotFound when no scan is queued for the folder,
+// and ErrScanProcessing when the scan is being processed.
+func (p *Processor) Remove(folder string) error {
+	return p.unlessClaimed(folder, func() error {
+		return p.store.Remove(folder)
+	})
+}
+
+// SetPriority changes the priority of the queued scan for the folder.
+// Returns ErrScanNotFound when no scan is queued for the folder,
+// and ErrScanProcessing when the scan is being processed.
+func (p *Processor) SetPriority(folder string, priority int) error {
+	return p.unlessClaimed(folder, func() error {
+		return p.store.SetPriority(folder, priority)
+	})
+}
+
+// Force moves the queued scan for the folder to the front of the queue,
+// skipping the minimum age and any retry backoff.
+// Returns ErrScanNotFound when no scan is queued for the folder,
+// and ErrScanProcessing when the scan is being processed.
+func (p *Processor) Force(folder string) error {
+	return p.unlessClaimed(folder, func() error {
+		return p.store.Force(folder)
+	})
+}
+
+// FailedScans returns all scans which exceeded the maximum amount of delivery attempts.
+func (p *Processor) FailedScans() ([]FailedScan, error) {
+	return p.store.GetFailed()
+}
+
+// Requeue moves a failed scan back into the queue.
+// Returns ErrScanNotFound when no failed scan exists for the folder.
+func (p *Processor) Requeue(folder string) error {
+	return p.store.Requeue(folder)
+}
+
 // Stats returns the shared stats instance for external counter access.
 func (p *Processor) Stats() *stats.Stats {
 	return p.stats
@@ -73,12 +203,14 @@ func (p *Processor) Stats() *stats.Stats {
 // CheckAnchors verifies that all configured anchor paths (files or directories)
 // exist. Returns true if all anchors are available (or none are configured).
 // Logs only on state transitions (available↔unavailable), not every call.
-// Must be called from a single goroutine (the scan loop).
 func (p *Processor) CheckAnchors() bool {
 	if len(p.anchors) == 0 {
 		return true
 	}
 
+	p.anchorMu.Lock()
+	defer p.anchorMu.Unlock()
+
 	allAvailable := true
 	for _, anchor := range p.anchors {
 		available := pathExists(anchor)
@@ -91,7 +223,10 @@ func (p *Processor) CheckAnchors() bool {
 		}
 
 		p.anchorState[anchor] = available
-		if !available {
+		if available {
+			p.stats.Anchors.Set(1, anchor)
+		} else {
+			p.stats.Anchors.Set(0, anchor)
 			allAvailable = false
 		}
 	}
@@ -99,91 +234,250 @@ func (p *Processor) CheckAnchors() bool {
 	return allAvailable
 }
 
-const processorTimeout = 90 * time.Second
-
-// CheckAvailability checks whether all targets are available.
-// If one target is not available, the error will return.
-func (*Processor) CheckAvailability(targets []autoscan.Target) error {
-	ctx, cancel := context.WithTimeout(context.Background(), processorTimeout)
-	defer cancel()
-
-	g, _ := errgroup.WithContext(ctx)
-
-	for _, target := range targets {
-		g.Go(func() error {
-			return target.Available()
-		})
-	}
-
-	if err := g.Wait(); err != nil {
-		return fmt.Errorf("check target availability: %w", err)
-	}
-	return nil
-}
-
-func (*Processor) callTargets(targets []autoscan.Target, scan autoscan.Scan) error {
+// callTargets sends the scan to all given targets in parallel and returns
+// the IDs of the targets which acknowledged it: the targets which scanned it
+// and the targets which reported that no library matched.
+func (p *Processor) callTargets(targets []autoscan.Target, scan autoscan.Scan) (scanned, skipped []string, err error) {
 	errs := make([]error, len(targets))
 	var wg sync.WaitGroup
 
 	for i, t := range targets {
 		wg.Go(func() {
-			errs[i] = t.Scan(scan)
+			errs[i] = p.scanTarget(t, scan)
 		})
 	}
 
 	wg.Wait()
 
-	var (
-		matched  int
-		skipped  int
-		firstErr error
-	)
-
-	for _, err := range errs {
+	var failed []error
+	for i, scanErr := range errs {
 		switch {
-		case err == nil:
-			matched++
-		case errors.Is(err, autoscan.ErrLibraryNotMatched):
-			skipped++
+		case scanErr == nil:
+			scanned = append(scanned, targets[i].ID())
+
+			p.stats.TargetScans.Add(1, targets[i].ID())
+			p.stats.ScanLatency.Observe(now().Sub(time.Unix(scan.Time, 0)), targets[i].ID())
+		case errors.Is(scanErr, autoscan.ErrLibraryNotMatched):
+			skipped = append(skipped, targets[i].ID())
 		default:
-			if firstErr == nil {
-				firstErr = err
-			}
+			failed = append(failed, scanErr)
 		}
 	}
 
-	if firstErr != nil {
-		return fmt.Errorf("call targets: %w", firstErr)
+	if len(failed) > 0 {
+		return scanned, skipped, fmt.Errorf("call targets: %w", errors.Join(failed...))
 	}
 
-	if matched == 0 && skipped > 0 {
+	if len(scanned) == 0 && len(skipped) > 0 {
 		log.Warn().
 			Str("folder", scan.Folder).
-			Int("targets_skipped", skipped).
+			Int("targets_skipped", len(skipped)).
 			Msg("No Targets Matched Scan")
 	}
 
-	return nil
+	return scanned, skipped, nil
+}
+
+// scanTarget sends the scan to the target within the limits of the target.
+func (p *Processor) scanTarget(t autoscan.Target, scan autoscan.Scan) error {
+	release, err := p.limiter(t.ID()).Acquire(context.Background())
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", t.ID(), err, autoscan.ErrFatal)
+	}
+	defer release()
+
+	start := now()
+	err = t.Scan(scan)
+	p.observe(t, "scan", start, err)
+	p.recordBreaker(t, err)
+	return err
+}
+
+// observe records the duration and the error class of a single target call.
+func (p *Processor) observe(target autoscan.Target, call string, start time.Time, err error) {
+	p.stats.TargetRequests.Observe(now().Sub(start), target.ID(), call)
+	if err != nil {
+		p.stats.TargetErrors.Add(1, target.ID(), errorClass(err))
+	}
+}
+
+// errorClass returns the metrics label for the class of a target error.
+func errorClass(err error) string {
+	switch {
+	case errors.Is(err, autoscan.ErrLibraryNotMatched):
+		return "library_not_matched"
+	case errors.Is(err, autoscan.ErrTargetUnavailable):
+		return "unavailable"
+	case errors.Is(err, autoscan.ErrTargetBusy):
+		return "busy"
+	case errors.Is(err, autoscan.ErrFatal):
+		return "fatal"
+	default:
+		return "other"
+	}
+}
+
+// pendingTargets returns the targets which have not yet acknowledged the scan.
+func (p *Processor) pendingTargets(targets []autoscan.Target, scan autoscan.Scan) ([]autoscan.Target, error) {
+	delivered, err := p.store.GetDelivered(scan)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]autoscan.Target, 0, len(targets))
+	for _, t := range targets {
+		if !delivered[t.ID()] {
+			pending = append(pending, t)
+		}
+	}
+
+	return pending, nil
+}
+
+// coalesces reports whether the target accepts coalesced scans.
+func coalesces(t autoscan.Target) bool {
+	c, ok := t.(autoscan.Coalescer)
+	return ok && c.Coalesce()
+}
+
+// coalesceTargets splits off the pending targets which accept coalesced scans
+// when a scan of an ancestor folder is queued, as these targets receive the
+// scan of the ancestor instead. Returns the remaining targets and the IDs of
+// the targets which were split off.
+// The split off targets only acknowledge the scan once they scanned the
+// ancestor, so the scan is not lost when the ancestor fails or is removed.
+func (p *Processor) coalesceTargets(pending []autoscan.Target, scan autoscan.Scan) ([]autoscan.Target, []string, error) {
+	if !p.coalesce || !slices.ContainsFunc(pending, coalesces) {
+		return pending, nil, nil
+	}
+
+	queued, err := p.store.HasQueuedAncestor(scan.Folder)
+	if err != nil || !queued {
+		return pending, nil, err
+	}
+
+	remaining := make([]autoscan.Target, 0, len(pending))
+	var covered []string
+	for _, t := range pending {
+		if coalesces(t) {
+			covered = append(covered, t.ID())
+		} else {
+			remaining = append(remaining, t)
+		}
+	}
+
+	return remaining, covered, nil
 }
 
-// Process picks the next available scan and dispatches it to all targets.
+// markCoalesced acknowledges the queued scans of all subfolders of the scan
+// for the targets which accept coalesced scans and scanned the folder.
+func (p *Processor) markCoalesced(targets []autoscan.Target, scanned []string, scan autoscan.Scan) error {
+	if !p.coalesce {
+		return nil
+	}
+
+	var ids []string
+	for _, t := range targets {
+		if coalesces(t) && slices.Contains(scanned, t.ID()) {
+			ids = append(ids, t.ID())
+		}
+	}
+
+	return p.store.MarkDescendantsDelivered(scan, ids)
+}
+
+// claim picks the next available scan which is not being processed by another worker.
+func (p *Processor) claim() (autoscan.Scan, error) {
+	p.claimMu.Lock()
+	defer p.claimMu.Unlock()
+
+	if p.claimed == nil {
+		p.claimed = make(map[string]bool)
+	}
+
+	scan, err := p.store.GetAvailableScan(p.minimumAge, slices.Collect(maps.Keys(p.claimed))...)
+	if err != nil {
+		return scan, err
+	}
+
+	p.claimed[scan.Folder] = true
+	return scan, nil
+}
+
+// unlessClaimed calls fn unless a worker is processing the scan of the folder.
+// No worker claims the scan while fn is running.
+func (p *Processor) unlessClaimed(folder string, fn func() error) error {
+	p.claimMu.Lock()
+	defer p.claimMu.Unlock()
+
+	if p.claimed[folder] {
+		return fmt.Errorf("%v: %w", folder, ErrScanProcessing)
+	}
+
+	return fn()
+}
+
+// unclaim allows other workers to pick the scan again.
+func (p *Processor) unclaim(scan autoscan.Scan) {
+	p.claimMu.Lock()
+	defer p.claimMu.Unlock()
+
+	delete(p.claimed, scan.Folder)
+}
+
+// Process picks the next available scan and dispatches it to all targets
+// which have not yet acknowledged it. The scan is removed from the datastore
+// once every target has acknowledged it.
+// Unavailable targets do not receive the scan, which is postponed for them
+// until they are available again. Returns ErrTargetUnavailable when all
+// targets are unavailable.
+// Concurrent calls never process the same scan at once.
 // Callers must call CheckAnchors() before Process() to gate on anchor availability.
 func (p *Processor) Process(targets []autoscan.Target) error {
-	// Protect against concurrent processing to prevent duplicate scan processing
-	p.processMu.Lock()
-	defer p.processMu.Unlock()
+	if p.allBlocked(targets) {
+		return fmt.Errorf("all targets: %w", autoscan.ErrTargetUnavailable)
+	}
+
+	scan, err := p.claim()
+	if err != nil {
+		return err
+	}
+	defer p.unclaim(scan)
 
-	scan, err := p.store.GetAvailableScan(p.minimumAge)
+	pending, err := p.pendingTargets(targets, scan)
 	if err != nil {
 		return err
 	}
 
-	// Fatal or Target Unavailable -> return original error
-	err = p.callTargets(targets, scan)
+	pending, covered, err := p.coalesceTargets(pending, scan)
 	if err != nil {
 		return err
 	}
 
+	allowed, deferred := p.allowTargets(pending)
+
+	// Record the targets which did receive the scan before handling errors.
+	scanned, skipped, callErr := p.callTargets(allowed, scan)
+	if err := p.store.MarkDelivered(scan, slices.Concat(scanned, skipped)); err != nil {
+		return err
+	}
+
+	if err := p.markCoalesced(allowed, scanned, scan); err != nil {
+		return err
+	}
+
+	switch {
+	case callErr != nil && !onlyDeferred(callErr):
+		// Any other error -> retry the scan later, or give up on it
+		return p.retry(scan, callErr)
+	case callErr != nil || len(deferred) > 0:
+		// Target Unavailable or Busy -> wait for the target, the scan is not to blame
+		return p.postpone(scan, deferred, callErr)
+	case len(covered) > 0:
+		// Queued ancestor -> wait until the ancestor has been scanned
+		return p.awaitAncestor(scan, covered)
+	}
+
 	err = p.store.Delete(scan)
 	if err != nil {
 		return err
@@ -193,6 +487,127 @@ func (p *Processor) Process(targets []autoscan.Target) error {
 	return nil
 }
 
+// maxRetryDelay caps the exponential backoff between retries.
+const maxRetryDelay = 24 * time.Hour
+
+// retryDelayFor returns the backoff delay after the given amount of failed attempts.
+func (p *Processor) retryDelayFor(attempts int) time.Duration {
+	delay := p.retryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+// retry records a failed delivery attempt for the scan. The scan is postponed
+// with an exponential backoff, or moved to the dead-letter table once it has
+// exceeded the maximum amount of attempts.
+func (p *Processor) retry(scan autoscan.Scan, cause error) error {
+	attempts, err := p.store.GetAttempts(scan)
+	switch {
+	case errors.Is(err, ErrScanNotFound):
+		// the scan is no longer queued, so there is nothing to retry
+		return nil
+	case err != nil:
+		return err
+	}
+
+	attempts++
+
+	if attempts >= p.maxAttempts {
+		if err := p.store.MoveToFailed(scan, attempts, cause.Error()); err != nil {
+			return err
+		}
+
+		p.stats.Failed.Add(1)
+		log.Error().
+			Err(cause).
+			Str("folder", scan.Folder).
+			Int("attempts", attempts).
+			Msg("Scan Failed")
+		return nil
+	}
+
+	delay := p.retryDelayFor(attempts)
+	if err := p.store.Retry(scan, attempts, cause.Error(), now().Add(delay)); err != nil {
+		return err
+	}
+
+	p.stats.Retried.Add(1)
+	log.Warn().
+		Err(cause).
+		Str("folder", scan.Folder).
+		Int("attempts", attempts).
+		Stringer("retry_in", delay).
+		Msg("Scan Retry Scheduled")
+	return nil
+}
+
+// postpone delays the scan for the unavailable and busy targets without
+// counting a failed delivery attempt.
+func (p *Processor) postpone(scan autoscan.Scan, deferred []autoscan.Target, cause error) error {
+	if err := p.store.Postpone(scan, now().Add(breakerCooldown)); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(deferred))
+	for _, t := range deferred {
+		ids = append(ids, t.ID())
+	}
+
+	log.Debug().
+		Err(cause).
+		Str("folder", scan.Folder).
+		Strs("deferred", ids).
+		Stringer("retry_in", breakerCooldown).
+		Msg("Scan Postponed")
+	return nil
+}
+
+// coalesceDelay is the delay before a scan waiting for the scan of an
+// ancestor folder is checked again.
+const coalesceDelay = 15 * time.Second
+
+// awaitAncestor delays the scan for the targets which receive the scan of
+// a queued ancestor folder instead. Once these targets scanned the ancestor,
+// the scan is acknowledged for them. Otherwise, the targets receive the scan
+// itself when the ancestor is no longer queued.
+func (p *Processor) awaitAncestor(scan autoscan.Scan, covered []string) error {
+	if err := p.store.Postpone(scan, now().Add(coalesceDelay)); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("folder", scan.Folder).
+		Strs("coalesced", covered).
+		Stringer("retry_in", coalesceDelay).
+		Msg("Scan Waiting For Parent")
+	return nil
+}
+
+// onlyDeferred reports whether every failed target call joined in the
+// error of callTargets reported ErrTargetUnavailable or ErrTargetBusy.
+func onlyDeferred(err error) bool {
+	joined, ok := errors.Unwrap(err).(interface{ Unwrap() []error })
+	if !ok {
+		return isDeferred(err)
+	}
+
+	for _, e := range joined.Unwrap() {
+		if !isDeferred(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDeferred reports whether the target asked to hold back the scan.
+func isDeferred(err error) bool {
+	return errors.Is(err, autoscan.ErrTargetUnavailable) || errors.Is(err, autoscan.ErrTargetBusy)
+}
+
 // Close closes the database connections
 func (p *Processor) Close() error {
 	if err := p.db.Close(); err != nil {
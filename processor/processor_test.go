@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudbox/autoscan"
 	"github.com/cloudbox/autoscan/stats"
@@ -48,9 +50,14 @@ func newTestProcessor(anchors []string) *Processor {
 
 // mockTarget is a minimal autoscan.Target for testing callTargets.
 type mockTarget struct {
+	id     string
 	scanFn func(autoscan.Scan) error
 }
 
+func (m *mockTarget) ID() string {
+	return m.id
+}
+
 func (m *mockTarget) Scan(scan autoscan.Scan) error {
 	return m.scanFn(scan)
 }
@@ -59,8 +66,17 @@ func (*mockTarget) Available() error {
 	return nil
 }
 
+// coalescingTarget is a mockTarget which accepts coalesced scans.
+type coalescingTarget struct {
+	mockTarget
+}
+
+func (*coalescingTarget) Coalesce() bool {
+	return true
+}
+
 func TestCallTargets(t *testing.T) {
-	p := &Processor{}
+	p := &Processor{stats: stats.New()}
 	scan := autoscan.Scan{Folder: "/media/movies"}
 
 	t.Run("AllMatch", func(t *testing.T) {
@@ -69,7 +85,7 @@ func TestCallTargets(t *testing.T) {
 			&mockTarget{scanFn: func(_ autoscan.Scan) error { return nil }},
 			&mockTarget{scanFn: func(_ autoscan.Scan) error { return nil }},
 		}
-		if err := p.callTargets(targets, scan); err != nil {
+		if _, _, err := p.callTargets(targets, scan); err != nil {
 			t.Errorf("expected nil error, got: %v", err)
 		}
 	})
@@ -87,7 +103,7 @@ func TestCallTargets(t *testing.T) {
 			}},
 		}
 		// All skipped — scan is consumed, not retried. No error returned.
-		if err := p.callTargets(targets, scan); err != nil {
+		if _, _, err := p.callTargets(targets, scan); err != nil {
 			t.Errorf("expected nil error when all targets skipped, got: %v", err)
 		}
 	})
@@ -100,7 +116,7 @@ func TestCallTargets(t *testing.T) {
 			}},
 			&mockTarget{scanFn: func(_ autoscan.Scan) error { return nil }},
 		}
-		if err := p.callTargets(targets, scan); err != nil {
+		if _, _, err := p.callTargets(targets, scan); err != nil {
 			t.Errorf("expected nil error for mixed match/skip, got: %v", err)
 		}
 	})
@@ -112,7 +128,7 @@ func TestCallTargets(t *testing.T) {
 				return errors.New("connection refused")
 			}},
 		}
-		err := p.callTargets(targets, scan)
+		_, _, err := p.callTargets(targets, scan)
 		if err == nil {
 			t.Fatal("expected non-nil error, got nil")
 		}
@@ -131,7 +147,7 @@ func TestCallTargets(t *testing.T) {
 				return errors.New("timeout")
 			}},
 		}
-		err := p.callTargets(targets, scan)
+		_, _, err := p.callTargets(targets, scan)
 		if err == nil {
 			t.Fatal("expected non-nil error, got nil")
 		}
@@ -144,6 +160,54 @@ func TestCallTargets(t *testing.T) {
 	})
 }
 
+func TestCallTargetsMetrics(t *testing.T) {
+	p := &Processor{stats: stats.New()}
+	scan := autoscan.Scan{Folder: "/media/movies", Time: time.Now().Add(-10 * time.Minute).Unix()}
+
+	targets := []autoscan.Target{
+		&mockTarget{id: "ok", scanFn: func(_ autoscan.Scan) error { return nil }},
+		&mockTarget{id: "skip", scanFn: func(_ autoscan.Scan) error {
+			return fmt.Errorf("%w: /tv", autoscan.ErrLibraryNotMatched)
+		}},
+		&mockTarget{id: "down", scanFn: func(_ autoscan.Scan) error {
+			return fmt.Errorf("down: %w", autoscan.ErrTargetUnavailable)
+		}},
+		&mockTarget{id: "busy", scanFn: func(_ autoscan.Scan) error {
+			return fmt.Errorf("scanning: %w", autoscan.ErrTargetBusy)
+		}},
+		&mockTarget{id: "fatal", scanFn: func(_ autoscan.Scan) error {
+			return fmt.Errorf("unauthorized: %w", autoscan.ErrFatal)
+		}},
+	}
+	_, _, _ = p.callTargets(targets, scan)
+
+	st := p.stats
+	if got := st.TargetScans.Value("ok"); got != 1 {
+		t.Errorf("expected 1 scan delivered to ok, got %d", got)
+	}
+	if got := st.TargetScans.Value("skip"); got != 0 {
+		t.Errorf("expected 0 scans delivered to skip, got %d", got)
+	}
+	for id, class := range map[string]string{
+		"skip":  "library_not_matched",
+		"down":  "unavailable",
+		"busy":  "busy",
+		"fatal": "fatal",
+	} {
+		if got := st.TargetErrors.Value(id, class); got != 1 {
+			t.Errorf("expected 1 %s error for %s, got %d", class, id, got)
+		}
+	}
+	for _, id := range []string{"ok", "skip", "down", "busy", "fatal"} {
+		if got := st.TargetRequests.With(id, "scan").Count(); got != 1 {
+			t.Errorf("expected 1 request observed for %s, got %d", id, got)
+		}
+	}
+	if got := st.ScanLatency.With("ok").Count(); got != 1 {
+		t.Errorf("expected 1 latency observed for ok, got %d", got)
+	}
+}
+
 func TestCheckAnchorsNoAnchors(t *testing.T) {
 	p := newTestProcessor(nil)
 
@@ -237,3 +301,457 @@ func TestCheckAnchorsStateTransitions(t *testing.T) {
 		t.Fatal("expected anchorState to be true after restore")
 	}
 }
+
+func TestProcessRetriesOnlyPendingTargets(t *testing.T) {
+	start := time.Now()
+	now = func() time.Time { return start }
+	t.Cleanup(func() { now = time.Now })
+
+	store := getDatastore(t)
+	p := &Processor{
+		store: store,
+		stats: stats.New(),
+	}
+
+	scan := autoscan.Scan{Folder: "/media/tv/Westworld", Time: time.Now().Add(-1 * time.Hour).Unix()}
+	if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+		t.Fatal(err)
+	}
+
+	var plexCalls, jellyfinCalls int
+	jellyfinErr := fmt.Errorf("offline: %w", autoscan.ErrTargetUnavailable)
+
+	targets := []autoscan.Target{
+		&mockTarget{id: "plex", scanFn: func(_ autoscan.Scan) error {
+			plexCalls++
+			return nil
+		}},
+		&mockTarget{id: "jellyfin", scanFn: func(_ autoscan.Scan) error {
+			jellyfinCalls++
+			return jellyfinErr
+		}},
+	}
+
+	// First attempt: plex acknowledges, jellyfin is unavailable.
+	if err := p.Process(targets); err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	// The scan is postponed without counting an attempt.
+	if attempts, err := store.GetAttempts(scan); err != nil || attempts != 0 {
+		t.Fatalf("expected 0 attempts, got %d (%v)", attempts, err)
+	}
+	if retried := p.stats.Snapshot().Retried; retried != 0 {
+		t.Errorf("expected postponed scan not to count as retried, got %d", retried)
+	}
+	if err := p.Process(targets); !errors.Is(err, autoscan.ErrNoScans) {
+		t.Fatalf("expected ErrNoScans while postponed, got: %v", err)
+	}
+
+	// Second attempt after the cooldown: only jellyfin is retried.
+	now = func() time.Time { return start.Add(breakerCooldown) }
+	jellyfinErr = nil
+	if err := p.Process(targets); err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	if plexCalls != 1 {
+		t.Errorf("expected plex to be called once, got %d", plexCalls)
+	}
+	if jellyfinCalls != 2 {
+		t.Errorf("expected jellyfin to be called twice, got %d", jellyfinCalls)
+	}
+
+	// Every target acknowledged the scan, so it must be removed.
+	scans, err := store.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scans) != 0 {
+		t.Errorf("expected scan to be removed, got %v", scans)
+	}
+}
+
+func TestProcessPostponesBusyTargets(t *testing.T) {
+	start := time.Now()
+	now = func() time.Time { return start }
+	t.Cleanup(func() { now = time.Now })
+
+	store := getDatastore(t)
+	p := &Processor{
+		store: store,
+		stats: stats.New(),
+	}
+
+	scan := autoscan.Scan{Folder: "/media/music/Daft Punk", Time: time.Now().Add(-1 * time.Hour).Unix()}
+	if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+		t.Fatal(err)
+	}
+
+	busyErr := fmt.Errorf("scan in progress: %w", autoscan.ErrTargetBusy)
+	targets := []autoscan.Target{
+		&mockTarget{id: "subsonic", scanFn: func(_ autoscan.Scan) error {
+			return busyErr
+		}},
+	}
+
+	if err := p.Process(targets); err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	// The scan is postponed without counting an attempt or opening the breaker.
+	if attempts, err := store.GetAttempts(scan); err != nil || attempts != 0 {
+		t.Fatalf("expected 0 attempts, got %d (%v)", attempts, err)
+	}
+	if state := p.breaker("subsonic").current(); state != breakerClosed {
+		t.Errorf("expected breaker to stay closed, got %v", state)
+	}
+	if err := p.Process(targets); !errors.Is(err, autoscan.ErrNoScans) {
+		t.Fatalf("expected ErrNoScans while postponed, got: %v", err)
+	}
+
+	now = func() time.Time { return start.Add(breakerCooldown) }
+	busyErr = nil
+	if err := p.Process(targets); err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	scans, err := store.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scans) != 0 {
+		t.Errorf("expected scan to be removed, got %v", scans)
+	}
+}
+
+func TestProcessCoalescesSubfolders(t *testing.T) {
+	testTime := time.Now()
+	now = func() time.Time {
+		return testTime
+	}
+
+	store := getDatastore(t)
+	store.coalesce = true
+	p := &Processor{
+		store:    store,
+		stats:    stats.New(),
+		coalesce: true,
+	}
+
+	var plexFolders, jellyfinFolders []string
+	targets := []autoscan.Target{
+		&coalescingTarget{mockTarget{id: "plex", scanFn: func(scan autoscan.Scan) error {
+			plexFolders = append(plexFolders, scan.Folder)
+			return nil
+		}}},
+		&mockTarget{id: "jellyfin", scanFn: func(scan autoscan.Scan) error {
+			jellyfinFolders = append(jellyfinFolders, scan.Folder)
+			return nil
+		}},
+	}
+
+	base := testTime.Add(-1 * time.Hour)
+	scans := []autoscan.Scan{
+		// The subfolder is processed first: plex waits for the parent.
+		{Folder: "/tv/Westworld/Season 1", Time: base.Add(-2 * time.Minute).Unix()},
+		{Folder: "/tv/Westworld", Time: base.Add(-90 * time.Second).Unix()},
+		// The parent is processed first: plex is done with the subfolder.
+		{Folder: "/movies/Tenet", Priority: 1, Time: base.Unix()},
+		{Folder: "/movies/Tenet/Extras", Time: base.Add(-1 * time.Minute).Unix()},
+	}
+	for _, scan := range scans {
+		if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for {
+		err := p.Process(targets)
+		if errors.Is(err, autoscan.ErrNoScans) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantPlex := []string{"/movies/Tenet", "/tv/Westworld"}
+	if !reflect.DeepEqual(plexFolders, wantPlex) {
+		t.Errorf("plex folders do not match: %v vs %v", plexFolders, wantPlex)
+	}
+
+	wantJellyfin := []string{"/movies/Tenet", "/tv/Westworld/Season 1", "/tv/Westworld", "/movies/Tenet/Extras"}
+	if !reflect.DeepEqual(jellyfinFolders, wantJellyfin) {
+		t.Errorf("jellyfin folders do not match: %v vs %v", jellyfinFolders, wantJellyfin)
+	}
+
+	// The subfolder waited for the parent, which plex has scanned since.
+	testTime = testTime.Add(coalesceDelay)
+	if err := p.Process(targets); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(plexFolders, wantPlex) {
+		t.Errorf("plex folders do not match: %v vs %v", plexFolders, wantPlex)
+	}
+
+	remaining, err := store.GetScansRemaining()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Errorf("expected no scans remaining, got %d", remaining)
+	}
+}
+
+func TestProcessDoesNotCoalesceIntoFileScans(t *testing.T) {
+	now = time.Now
+
+	store := getDatastore(t)
+	store.coalesce = true
+	p := &Processor{
+		store:    store,
+		stats:    stats.New(),
+		coalesce: true,
+	}
+
+	var folders []string
+	targets := []autoscan.Target{
+		&coalescingTarget{mockTarget{id: "jellyfin", scanFn: func(scan autoscan.Scan) error {
+			folders = append(folders, scan.Folder)
+			return nil
+		}}},
+	}
+
+	base := time.Now().Add(-1 * time.Hour)
+	scans := []autoscan.Scan{
+		{Folder: "/tv/Westworld/Season 1", Time: base.Add(-1 * time.Minute).Unix()},
+		// only a single file of the parent changed
+		{Folder: "/tv/Westworld", RelativePath: "tvshow.nfo", Time: base.Unix()},
+	}
+	if err := store.Upsert(scans); err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		err := p.Process(targets)
+		if errors.Is(err, autoscan.ErrNoScans) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"/tv/Westworld/Season 1", "/tv/Westworld"}
+	if !reflect.DeepEqual(folders, want) {
+		t.Errorf("folders do not match: %v vs %v", folders, want)
+	}
+}
+
+func TestProcessCoalescedSubfolderOutlivesParent(t *testing.T) {
+	type Test struct {
+		Name   string
+		Parent func(p *Processor, targets []autoscan.Target) error
+	}
+
+	testCases := []Test{
+		{
+			Name: "Parent failed",
+			Parent: func(p *Processor, targets []autoscan.Target) error {
+				return p.Process(targets)
+			},
+		},
+		{
+			Name: "Parent removed",
+			Parent: func(p *Processor, _ []autoscan.Target) error {
+				return p.Remove("/tv/Westworld")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			testTime := time.Now()
+			now = func() time.Time {
+				return testTime
+			}
+
+			store := getDatastore(t)
+			store.coalesce = true
+			p := &Processor{
+				store:       store,
+				stats:       stats.New(),
+				coalesce:    true,
+				maxAttempts: 1,
+			}
+
+			var folders []string
+			targets := []autoscan.Target{
+				&coalescingTarget{mockTarget{id: "plex", scanFn: func(scan autoscan.Scan) error {
+					if scan.Folder == "/tv/Westworld" {
+						return fmt.Errorf("400 Bad Request: %w", autoscan.ErrFatal)
+					}
+
+					folders = append(folders, scan.Folder)
+					return nil
+				}}},
+			}
+
+			base := testTime.Add(-1 * time.Hour)
+			scans := []autoscan.Scan{
+				{Folder: "/tv/Westworld/Season 1", Time: base.Add(-2 * time.Minute).Unix()},
+				{Folder: "/tv/Westworld", Time: base.Unix()},
+			}
+			for _, scan := range scans {
+				if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The subfolder is processed first and waits for the parent.
+			if err := p.Process(targets); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tc.Parent(p, targets); err != nil {
+				t.Fatal(err)
+			}
+
+			testTime = testTime.Add(coalesceDelay)
+			if err := p.Process(targets); err != nil {
+				t.Fatal(err)
+			}
+
+			want := []string{"/tv/Westworld/Season 1"}
+			if !reflect.DeepEqual(folders, want) {
+				t.Errorf("folders do not match: %v vs %v", folders, want)
+			}
+		})
+	}
+}
+
+func TestProcessScanRemovedWhileProcessing(t *testing.T) {
+	type Test struct {
+		Name string
+		Err  error
+	}
+
+	testCases := []Test{
+		{Name: "Scanned"},
+		{Name: "Failed", Err: fmt.Errorf("400 Bad Request: %w", autoscan.ErrFatal)},
+		{Name: "Unavailable", Err: fmt.Errorf("503 Service Unavailable: %w", autoscan.ErrTargetUnavailable)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			now = time.Now
+
+			store := getDatastore(t)
+			p := &Processor{
+				store:       store,
+				stats:       stats.New(),
+				maxAttempts: 5,
+				retryDelay:  time.Minute,
+			}
+
+			scan := autoscan.Scan{Folder: "/media/movies/Tenet", Time: time.Now().Add(-1 * time.Hour).Unix()}
+			if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+				t.Fatal(err)
+			}
+
+			targets := []autoscan.Target{
+				&mockTarget{id: "plex", scanFn: func(scan autoscan.Scan) error {
+					if err := store.Remove(scan.Folder); err != nil {
+						t.Fatal(err)
+					}
+
+					return tc.Err
+				}},
+			}
+
+			if err := p.Process(targets); err != nil {
+				t.Fatalf("expected nil error, got: %v", err)
+			}
+
+			remaining, err := store.GetScansRemaining()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if remaining != 0 {
+				t.Errorf("expected no scans remaining, got %d", remaining)
+			}
+		})
+	}
+}
+
+func TestProcessMovesFailingScanToFailed(t *testing.T) {
+	testTime := time.Now()
+	now = func() time.Time {
+		return testTime
+	}
+
+	store := getDatastore(t)
+	p := &Processor{
+		store:       store,
+		stats:       stats.New(),
+		maxAttempts: 2,
+		retryDelay:  time.Minute,
+	}
+
+	scan := autoscan.Scan{Folder: "/media/movies/Tenet", Time: testTime.Add(-1 * time.Hour).Unix()}
+	if err := store.Upsert([]autoscan.Scan{scan}); err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []autoscan.Target{
+		&mockTarget{id: "plex", scanFn: func(_ autoscan.Scan) error {
+			return fmt.Errorf("400 Bad Request: %w", autoscan.ErrFatal)
+		}},
+	}
+
+	// First failure schedules a retry instead of stopping the processor.
+	if err := p.Process(targets); err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	if err := p.Process(targets); !errors.Is(err, autoscan.ErrNoScans) {
+		t.Fatalf("expected ErrNoScans while backing off, got: %v", err)
+	}
+
+	// Second failure, after the backoff, moves the scan to the dead-letter table.
+	testTime = testTime.Add(time.Minute)
+	if err := p.Process(targets); err != nil {
+		t.Fatalf("expected nil error, got: %v", err)
+	}
+
+	failed, err := p.FailedScans()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(failed) != 1 || failed[0].Folder != scan.Folder || failed[0].Attempts != 2 {
+		t.Errorf("expected scan to be moved to failed, got: %+v", failed)
+	}
+
+	snap := p.stats.Snapshot()
+	if snap.Retried != 1 || snap.Failed != 1 {
+		t.Errorf("expected 1 retried and 1 failed, got: %+v", snap)
+	}
+}
+
+func TestRetryDelayFor(t *testing.T) {
+	p := &Processor{retryDelay: time.Minute}
+
+	for attempts, want := range map[int]time.Duration{
+		1:   time.Minute,
+		2:   2 * time.Minute,
+		4:   8 * time.Minute,
+		100: maxRetryDelay,
+	} {
+		if got := p.retryDelayFor(attempts); got != want {
+			t.Errorf("attempts %d: expected %v, got %v", attempts, want, got)
+		}
+	}
+}
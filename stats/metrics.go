@@ -0,0 +1,168 @@
+package stats
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// labelSeparator joins label values into a map key. It cannot occur in paths or IDs.
+const labelSeparator = "\x00"
+
+// series is a set of values partitioned by label values, created on first use.
+type series[T any] struct {
+	labels []string
+
+	mu     sync.RWMutex
+	values map[string]*T
+	create func() *T
+}
+
+func newSeries[T any](create func() *T, labels ...string) *series[T] {
+	return &series[T]{
+		labels: labels,
+		values: make(map[string]*T),
+		create: create,
+	}
+}
+
+// get returns the value for the given label values, creating it when needed.
+func (s *series[T]) get(values ...string) *T {
+	key := strings.Join(values, labelSeparator)
+
+	s.mu.RLock()
+	v, ok := s.values[key]
+	s.mu.RUnlock()
+
+	if ok {
+		return v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v, ok := s.values[key]; ok {
+		return v
+	}
+
+	v = s.create()
+	s.values[key] = v
+	return v
+}
+
+// each calls fn for every value in a stable order.
+func (s *series[T]) each(fn func(values []string, v *T)) {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	s.mu.RUnlock()
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		s.mu.RLock()
+		v := s.values[key]
+		s.mu.RUnlock()
+
+		fn(strings.Split(key, labelSeparator), v)
+	}
+}
+
+// CounterVec is a set of counters partitioned by label values.
+type CounterVec struct {
+	*series[atomic.Int64]
+}
+
+// NewCounterVec returns an empty CounterVec with the given label names.
+func NewCounterVec(labels ...string) *CounterVec {
+	return &CounterVec{newSeries(func() *atomic.Int64 { return new(atomic.Int64) }, labels...)}
+}
+
+// Add adds n to the counter with the given label values.
+func (c *CounterVec) Add(n int64, values ...string) {
+	c.get(values...).Add(n)
+}
+
+// Value returns the counter with the given label values.
+func (c *CounterVec) Value(values ...string) int64 {
+	return c.get(values...).Load()
+}
+
+// GaugeVec is a set of gauges partitioned by label values.
+type GaugeVec struct {
+	*series[atomic.Int64]
+}
+
+// NewGaugeVec returns an empty GaugeVec with the given label names.
+func NewGaugeVec(labels ...string) *GaugeVec {
+	return &GaugeVec{newSeries(func() *atomic.Int64 { return new(atomic.Int64) }, labels...)}
+}
+
+// Set sets the gauge with the given label values to v.
+func (g *GaugeVec) Set(v int64, values ...string) {
+	g.get(values...).Store(v)
+}
+
+// Value returns the gauge with the given label values.
+func (g *GaugeVec) Value(values ...string) int64 {
+	return g.get(values...).Load()
+}
+
+// Histogram counts observed durations in cumulative buckets.
+type Histogram struct {
+	buckets []float64 // upper bounds in seconds, ascending
+	counts  []atomic.Int64
+	count   atomic.Int64
+	sum     atomic.Int64 // nanoseconds
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		buckets: buckets,
+		counts:  make([]atomic.Int64, len(buckets)),
+	}
+}
+
+// Observe records a single duration.
+func (h *Histogram) Observe(d time.Duration) {
+	seconds := d.Seconds()
+	for i, upper := range h.buckets {
+		if seconds <= upper {
+			h.counts[i].Add(1)
+			break
+		}
+	}
+
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+// Count returns the amount of observed durations.
+func (h *Histogram) Count() int64 {
+	return h.count.Load()
+}
+
+// HistogramVec is a set of histograms partitioned by label values.
+type HistogramVec struct {
+	*series[Histogram]
+}
+
+// NewHistogramVec returns an empty HistogramVec with the given bucket upper bounds
+// (in seconds) and label names.
+func NewHistogramVec(buckets []float64, labels ...string) *HistogramVec {
+	return &HistogramVec{newSeries(func() *Histogram { return newHistogram(buckets) }, labels...)}
+}
+
+// Observe records a single duration in the histogram with the given label values.
+func (h *HistogramVec) Observe(d time.Duration, values ...string) {
+	h.get(values...).Observe(d)
+}
+
+// With returns the histogram with the given label values.
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.get(values...)
+}
@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes all metrics in the Prometheus text exposition format.
+func (s *Stats) WritePrometheus(w io.Writer) error {
+	e := &exposition{w: w}
+
+	e.single("autoscan_scans_queued", "gauge", "Scans waiting in the queue.", s.Queued.Load())
+	e.single("autoscan_scans_received_total", "counter", "Scans received from all triggers.", s.Received.Load())
+	e.single("autoscan_scans_processed_total", "counter", "Scans delivered to all targets.", s.Processed.Load())
+	e.single("autoscan_scans_retried_total", "counter", "Scans scheduled for another delivery attempt.", s.Retried.Load())
+	e.single("autoscan_scans_failed_total", "counter", "Scans moved to the failed scans after the last attempt.", s.Failed.Load())
+
+	e.vec("autoscan_trigger_scans_total", "counter", "Scans received per trigger.", s.TriggerScans.series)
+	e.vec("autoscan_target_scans_total", "counter", "Scans delivered per target.", s.TargetScans.series)
+	e.vec("autoscan_target_errors_total", "counter", "Failed target calls per target and error class.", s.TargetErrors.series)
+	e.vec("autoscan_anchor_available", "gauge", "Whether the anchor path is available.", s.Anchors.series)
+	e.vec("autoscan_target_state", "gauge", "Circuit breaker state per target (0 closed, 1 open, 2 half-open).", s.TargetState.series)
+
+	e.histograms("autoscan_target_request_duration_seconds", "Duration of target calls.", s.TargetRequests)
+	e.histograms("autoscan_scan_latency_seconds", "Time from enqueuing a scan until a target received it.", s.ScanLatency)
+
+	return e.err
+}
+
+// exposition writes metric families and remembers the first write error.
+type exposition struct {
+	w   io.Writer
+	err error
+}
+
+func (e *exposition) printf(format string, args ...any) {
+	if e.err != nil {
+		return
+	}
+
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
+
+func (e *exposition) header(name, kind, help string) {
+	e.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (e *exposition) single(name, kind, help string, value int64) {
+	e.header(name, kind, help)
+	e.printf("%s %d\n", name, value)
+}
+
+func (e *exposition) vec(name, kind, help string, s *series[atomic.Int64]) {
+	e.header(name, kind, help)
+	s.each(func(values []string, v *atomic.Int64) {
+		e.printf("%s%s %d\n", name, formatLabels(s.labels, values), v.Load())
+	})
+}
+
+func (e *exposition) histograms(name, help string, h *HistogramVec) {
+	e.header(name, "histogram", help)
+	h.each(func(values []string, v *Histogram) {
+		var cumulative int64
+		for i, upper := range v.buckets {
+			cumulative += v.counts[i].Load()
+			le := strconv.FormatFloat(upper, 'g', -1, 64)
+			e.printf("%s_bucket%s %d\n", name, bucketLabels(h.labels, values, le), cumulative)
+		}
+
+		// Observations may land between the loads; never report fewer than the buckets.
+		count := max(v.count.Load(), cumulative)
+		e.printf("%s_bucket%s %d\n", name, bucketLabels(h.labels, values, "+Inf"), count)
+
+		labels := formatLabels(h.labels, values)
+		e.printf("%s_sum%s %s\n", name, labels, formatSeconds(time.Duration(v.sum.Load())))
+		e.printf("%s_count%s %d\n", name, labels, count)
+	})
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func bucketLabels(names, values []string, le string) string {
+	return formatLabels(slices.Concat(names, []string{"le"}), slices.Concat(values, []string{le}))
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	s := New()
+
+	s.Queued.Store(3)
+	s.Received.Add(5)
+	s.TriggerScans.Add(4, "sonarr")
+	s.TriggerScans.Add(1, "inotify")
+	s.TargetErrors.Add(2, "plex:http://localhost:32400", "unavailable")
+	s.Anchors.Set(1, `/mnt/"unionfs"/mounted.bin`)
+	s.TargetState.Set(1, "plex:http://localhost:32400")
+	s.TargetRequests.Observe(200*time.Millisecond, "plex:http://localhost:32400", "scan")
+	s.TargetRequests.Observe(3*time.Second, "plex:http://localhost:32400", "scan")
+
+	var sb strings.Builder
+	if err := s.WritePrometheus(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	want := []string{
+		"# TYPE autoscan_scans_queued gauge\nautoscan_scans_queued 3\n",
+		"autoscan_scans_received_total 5\n",
+		"autoscan_scans_processed_total 0\n",
+		// label sets are sorted
+		"autoscan_trigger_scans_total{trigger=\"inotify\"} 1\nautoscan_trigger_scans_total{trigger=\"sonarr\"} 4\n",
+		`autoscan_target_errors_total{target="plex:http://localhost:32400",class="unavailable"} 2` + "\n",
+		`autoscan_anchor_available{path="/mnt/\"unionfs\"/mounted.bin"} 1` + "\n",
+		`autoscan_target_state{target="plex:http://localhost:32400"} 1` + "\n",
+		"# TYPE autoscan_target_request_duration_seconds histogram\n",
+		`autoscan_target_request_duration_seconds_bucket{target="plex:http://localhost:32400",call="scan",le="0.1"} 0` + "\n",
+		`autoscan_target_request_duration_seconds_bucket{target="plex:http://localhost:32400",call="scan",le="0.25"} 1` + "\n",
+		`autoscan_target_request_duration_seconds_bucket{target="plex:http://localhost:32400",call="scan",le="5"} 2` + "\n",
+		`autoscan_target_request_duration_seconds_bucket{target="plex:http://localhost:32400",call="scan",le="+Inf"} 2` + "\n",
+		`autoscan_target_request_duration_seconds_sum{target="plex:http://localhost:32400",call="scan"} 3.2` + "\n",
+		`autoscan_target_request_duration_seconds_count{target="plex:http://localhost:32400",call="scan"} 2` + "\n",
+		"# TYPE autoscan_scan_latency_seconds histogram\n",
+	}
+
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("output does not contain %q\n%s", w, out)
+		}
+	}
+}
+
+func TestHistogramOutOfRange(t *testing.T) {
+	h := NewHistogramVec([]float64{1, 10}, "target")
+	h.Observe(time.Minute, "x")
+
+	if got := h.With("x").Count(); got != 1 {
+		t.Errorf("expected Count=1, got %d", got)
+	}
+
+	var sb strings.Builder
+	e := &exposition{w: &sb}
+	e.histograms("latency", "help", h)
+
+	for _, w := range []string{
+		`latency_bucket{target="x",le="10"} 0`,
+		`latency_bucket{target="x",le="+Inf"} 1`,
+		`latency_sum{target="x"} 60`,
+	} {
+		if !strings.Contains(sb.String(), w) {
+			t.Errorf("output does not contain %q\n%s", w, sb.String())
+		}
+	}
+}
@@ -3,16 +3,49 @@ package stats
 
 import "sync/atomic"
 
+// Bucket upper bounds (in seconds) of the duration histograms.
+var (
+	requestBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	latencyBuckets = []float64{60, 300, 600, 900, 1800, 3600, 7200, 21600, 86400, 604800}
+)
+
 // Stats holds atomic counters for scan processing metrics.
 type Stats struct {
 	Received  atomic.Int64
 	Processed atomic.Int64
 	Retried   atomic.Int64
+	Failed    atomic.Int64
+
+	// TriggerScans counts the scans received per trigger.
+	TriggerScans *CounterVec
+	// TargetScans counts the scans delivered per target.
+	TargetScans *CounterVec
+	// TargetErrors counts the failed target calls per target and error class.
+	TargetErrors *CounterVec
+	// TargetRequests observes the duration of target calls per target and call.
+	TargetRequests *HistogramVec
+	// ScanLatency observes the time from enqueue to delivery per target.
+	ScanLatency *HistogramVec
+	// Anchors reports the availability of every anchor path.
+	Anchors *GaugeVec
+	// TargetState reports the circuit breaker state of every target:
+	// 0 closed, 1 open, 2 half-open.
+	TargetState *GaugeVec
+	// Queued is the amount of scans in the queue, as last reported.
+	Queued atomic.Int64
 }
 
 // New returns a zero-valued Stats ready for use.
 func New() *Stats {
-	return &Stats{}
+	return &Stats{
+		TriggerScans:   NewCounterVec("trigger"),
+		TargetScans:    NewCounterVec("target"),
+		TargetErrors:   NewCounterVec("target", "class"),
+		TargetRequests: NewHistogramVec(requestBuckets, "target", "call"),
+		ScanLatency:    NewHistogramVec(latencyBuckets, "target"),
+		Anchors:        NewGaugeVec("path"),
+		TargetState:    NewGaugeVec("target"),
+	}
 }
 
 // Snapshot is a plain-struct copy of all counters at a point in time.
@@ -20,6 +53,7 @@ type Snapshot struct {
 	Received  int64
 	Processed int64
 	Retried   int64
+	Failed    int64
 }
 
 // Snapshot reads all counters atomically and returns a plain copy.
@@ -28,5 +62,6 @@ func (s *Stats) Snapshot() Snapshot {
 		Received:  s.Received.Load(),
 		Processed: s.Processed.Load(),
 		Retried:   s.Retried.Load(),
+		Failed:    s.Failed.Load(),
 	}
 }
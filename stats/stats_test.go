@@ -26,6 +26,7 @@ func TestIncrementAndSnapshot(t *testing.T) {
 	s.Received.Add(10)
 	s.Processed.Add(7)
 	s.Retried.Add(2)
+	s.Failed.Add(1)
 
 	snap := s.Snapshot()
 
@@ -38,6 +39,9 @@ func TestIncrementAndSnapshot(t *testing.T) {
 	if snap.Retried != 2 {
 		t.Errorf("expected Retried=2, got %d", snap.Retried)
 	}
+	if snap.Failed != 1 {
+		t.Errorf("expected Failed=1, got %d", snap.Failed)
+	}
 }
 
 func TestConcurrentIncrements(t *testing.T) {
@@ -0,0 +1,167 @@
+// Package audiobookshelf provides an autoscan target for Audiobookshelf servers.
+package audiobookshelf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/httpclient"
+)
+
+type apiClient struct {
+	client  *http.Client
+	log     zerolog.Logger
+	baseURL string
+	token   string
+}
+
+func newAPIClient(baseURL, token string, log zerolog.Logger) apiClient {
+	return apiClient{
+		client:  httpclient.New(),
+		log:     log,
+		baseURL: baseURL,
+		token:   token,
+	}
+}
+
+func (c apiClient) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.client.Do(req) //nolint:gosec // URL is user-configured in app config, SSRF is intentional
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, autoscan.ErrTargetUnavailable)
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		res.Body = autoscan.LimitReadCloser(res.Body)
+		return res, nil
+	}
+
+	c.log.Trace().
+		Stringer("request_url", res.Request.URL).
+		Int("response_status", res.StatusCode).
+		Msg("Request failed")
+
+	// statusCode not in the 2xx range, close response
+	_ = res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("invalid audiobookshelf token: %s: %w", res.Status, autoscan.ErrFatal)
+	case http.StatusNotFound,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrTargetUnavailable)
+	default:
+		return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrFatal)
+	}
+}
+
+func (c apiClient) Available() error {
+	// create request
+	reqURL := autoscan.JoinURL(c.baseURL, "healthcheck")
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, reqURL, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed creating availability request: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("availability: %w", err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+	return nil
+}
+
+type library struct {
+	ID        string
+	Name      string
+	Path      string
+	MediaType string
+}
+
+// Libraries returns a library per folder of the Audiobookshelf libraries.
+func (c apiClient) Libraries() ([]library, error) {
+	// create request
+	reqURL := autoscan.JoinURL(c.baseURL, "api", "libraries")
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, reqURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating libraries request: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("libraries: %w", err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	// decode response
+	type Response struct {
+		Libraries []struct {
+			ID        string `json:"id"`
+			Name      string `json:"name"`
+			MediaType string `json:"mediaType"`
+			Folders   []struct {
+				FullPath string `json:"fullPath"`
+			} `json:"folders"`
+		} `json:"libraries"`
+	}
+
+	resp := new(Response)
+	if err := json.NewDecoder(res.Body).Decode(resp); err != nil {
+		return nil, fmt.Errorf("failed decoding libraries request response: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	// process response
+	libraries := make([]library, 0)
+	for _, lib := range resp.Libraries {
+		for _, folder := range lib.Folders {
+			folderPath := folder.FullPath
+			// Add trailing slash if there is none.
+			if folderPath != "" && folderPath[len(folderPath)-1] != '/' {
+				folderPath += "/"
+			}
+
+			libraries = append(libraries, library{
+				ID:        lib.ID,
+				Name:      lib.Name,
+				Path:      folderPath,
+				MediaType: lib.MediaType,
+			})
+		}
+	}
+
+	return libraries, nil
+}
+
+// Scan starts a scan of the library, which only rescans the folders
+// that changed since the previous scan.
+func (c apiClient) Scan(libraryID string) error {
+	// create request
+	reqURL := autoscan.JoinURL(c.baseURL, "api", "libraries", libraryID, "scan")
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, reqURL, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed creating scan request: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("scan: %w", err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+	return nil
+}
@@ -0,0 +1,158 @@
+package audiobookshelf
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// Config holds configuration for the Audiobookshelf target.
+type Config struct {
+	Name      string             `yaml:"name"`
+	URL       string             `yaml:"url"`
+	Token     string             `yaml:"token"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Verbosity string             `yaml:"verbosity"`
+
+	Limits autoscan.TargetLimits `yaml:",inline"`
+}
+
+type target struct {
+	name      string
+	url       string
+	token     string
+	libraries []library
+
+	// scanned holds the unix time of the last scan sent per library ID
+	scanned   map[string]int64
+	scannedMu *sync.Mutex
+
+	log     zerolog.Logger
+	rewrite autoscan.Rewriter
+	api     apiClient
+}
+
+// New creates an Audiobookshelf target from the given Config.
+func New(cfg Config) (autoscan.Target, error) {
+	logger := autoscan.GetLogger(cfg.Verbosity).With().
+		Str("target", "audiobookshelf").
+		Str("url", cfg.URL).
+		Logger()
+
+	rewriter, err := autoscan.NewRewriter(cfg.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("create rewriter: %w", err)
+	}
+
+	api := newAPIClient(cfg.URL, cfg.Token, logger)
+
+	libraries, err := api.Libraries()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug().
+		Interface("libraries", libraries).
+		Msg("Libraries Retrieved")
+
+	return &target{
+		name:      cfg.Name,
+		url:       cfg.URL,
+		token:     cfg.Token,
+		libraries: libraries,
+
+		scanned:   make(map[string]int64),
+		scannedMu: new(sync.Mutex),
+
+		log:     logger,
+		rewrite: rewriter,
+		api:     api,
+	}, nil
+}
+
+func (t target) ID() string {
+	if t.name != "" {
+		return "audiobookshelf:" + t.name
+	}
+
+	return "audiobookshelf:" + t.url
+}
+
+// Coalesce reports that the target accepts coalesced scans,
+// as Audiobookshelf scans the whole library of a folder.
+func (target) Coalesce() bool {
+	return true
+}
+
+func (t target) Available() error {
+	return t.api.Available()
+}
+
+func (t target) Scan(scan autoscan.Scan) error {
+	// determine library for this scan
+	scanFolder := t.rewrite(scan.Folder)
+
+	lib, err := t.getScanLibrary(scanFolder)
+	if err != nil {
+		t.log.Debug().Str("folder", scanFolder).Msg("Library Not Matched")
+		return fmt.Errorf("%w: %s", autoscan.ErrLibraryNotMatched, scanFolder)
+	}
+
+	logger := t.log.With().
+		Str("path", scanFolder).
+		Str("library", lib.Name).
+		Logger()
+
+	// a library scan sent after the change already picks it up
+	if t.scannedSince(lib.ID, scan.Time) {
+		logger.Debug().Msg("Scan Covered By Library Scan")
+		return nil
+	}
+
+	// send scan request
+	logger.Debug().Msg("Scan Sending")
+
+	sent := time.Now().Unix()
+	if err := t.api.Scan(lib.ID); err != nil {
+		return err
+	}
+
+	t.setScanned(lib.ID, sent)
+
+	logger.Info().Msg("Scan Sent")
+	return nil
+}
+
+func (t target) getScanLibrary(folder string) (*library, error) {
+	for _, l := range t.libraries {
+		if strings.HasPrefix(folder, l.Path) {
+			return &l, nil
+		}
+		// Library root path
+		if autoscan.CleanedPathEqual(folder, l.Path) {
+			return &l, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%v: failed determining library", folder)
+}
+
+// scannedSince reports whether a scan of the library was sent after the given unix time.
+func (t target) scannedSince(libraryID string, since int64) bool {
+	t.scannedMu.Lock()
+	defer t.scannedMu.Unlock()
+
+	return since < t.scanned[libraryID]
+}
+
+func (t target) setScanned(libraryID string, sent int64) {
+	t.scannedMu.Lock()
+	defer t.scannedMu.Unlock()
+
+	t.scanned[libraryID] = max(t.scanned[libraryID], sent)
+}
@@ -0,0 +1,167 @@
+package audiobookshelf
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+)
+
+const librariesResponse = `{"libraries": [
+	{"id": "lib_audiobooks", "name": "Audiobooks", "mediaType": "book", "folders": [
+		{"id": "fol_1", "fullPath": "/audiobooks"},
+		{"id": "fol_2", "fullPath": "/books/audio/"}
+	]},
+	{"id": "lib_podcasts", "name": "Podcasts", "mediaType": "podcast", "folders": [
+		{"id": "fol_3", "fullPath": "/podcasts"}
+	]}
+]}`
+
+// newServer returns an Audiobookshelf server which records the libraries it scans.
+func newServer(t *testing.T, scanned *[]string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthcheck" {
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/libraries":
+			_, _ = w.Write([]byte(librariesResponse))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/libraries/lib_audiobooks/scan",
+			r.Method == http.MethodPost && r.URL.Path == "/api/libraries/lib_podcasts/scan":
+			*scanned = append(*scanned, r.URL.Path)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestScan(t *testing.T) {
+	type Test struct {
+		Name     string
+		Folder   string
+		Expected []string
+		Err      error
+	}
+
+	testCases := []Test{
+		{
+			Name:     "Audiobook",
+			Folder:   "/mnt/unionfs/Media/audiobooks/Frank Herbert/Dune",
+			Expected: []string{"/api/libraries/lib_audiobooks/scan"},
+		},
+		{
+			Name:     "Second folder of a library",
+			Folder:   "/mnt/unionfs/Media/books/audio/Dune",
+			Expected: []string{"/api/libraries/lib_audiobooks/scan"},
+		},
+		{
+			Name:     "Library root",
+			Folder:   "/mnt/unionfs/Media/podcasts",
+			Expected: []string{"/api/libraries/lib_podcasts/scan"},
+		},
+		{
+			Name:   "Library not matched",
+			Folder: "/mnt/unionfs/Media/books/ebooks/Dune",
+			Err:    autoscan.ErrLibraryNotMatched,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var scanned []string
+			server := newServer(t, &scanned)
+			defer server.Close()
+
+			target, err := New(Config{
+				URL:   server.URL,
+				Token: "secret",
+				Rewrite: []autoscan.Rewrite{{
+					From: "^/mnt/unionfs/Media/",
+					To:   "/",
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := target.Available(); err != nil {
+				t.Fatalf("expected target to be available: %v", err)
+			}
+
+			err = target.Scan(autoscan.Scan{Folder: tc.Folder})
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error %v, got %v", tc.Err, err)
+			}
+
+			if !reflect.DeepEqual(tc.Expected, scanned) {
+				t.Errorf("expected scans %v, got %v", tc.Expected, scanned)
+			}
+		})
+	}
+}
+
+func TestNewInvalidToken(t *testing.T) {
+	var scanned []string
+	server := newServer(t, &scanned)
+	defer server.Close()
+
+	_, err := New(Config{URL: server.URL, Token: "wrong"})
+	if !errors.Is(err, autoscan.ErrFatal) {
+		t.Errorf("expected ErrFatal, got %v", err)
+	}
+}
+
+func TestScanCoveredByLibraryScan(t *testing.T) {
+	var scanned []string
+	server := newServer(t, &scanned)
+	defer server.Close()
+
+	target, err := New(Config{
+		URL:   server.URL,
+		Token: "secret",
+		Rewrite: []autoscan.Rewrite{{
+			From: "^/mnt/unionfs/Media/",
+			To:   "/",
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := time.Now().Add(-1 * time.Minute).Unix()
+	scans := []autoscan.Scan{
+		{Folder: "/mnt/unionfs/Media/audiobooks/Frank Herbert/Dune", Time: changed},
+		// changed before the first library scan was sent
+		{Folder: "/mnt/unionfs/Media/books/audio/Children of Dune", Time: changed},
+		{Folder: "/mnt/unionfs/Media/podcasts/Darknet Diaries", Time: changed},
+		// changed after the first library scan was sent
+		{Folder: "/mnt/unionfs/Media/books/audio/Children of Dune", Time: time.Now().Add(time.Minute).Unix()},
+	}
+	for _, scan := range scans {
+		if err := target.Scan(scan); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{
+		"/api/libraries/lib_audiobooks/scan",
+		"/api/libraries/lib_podcasts/scan",
+		"/api/libraries/lib_audiobooks/scan",
+	}
+	if !reflect.DeepEqual(expected, scanned) {
+		t.Errorf("expected scans %v, got %v", expected, scanned)
+	}
+}
@@ -86,7 +86,7 @@ func (c apiClient) Available() error {
 	return nil
 }
 
-func (c apiClient) Scan(folder, path string) error {
+func (c apiClient) Scan(folder string, paths []string) error {
 	// create request
 	triggerURL := autoscan.JoinURL(c.baseURL, "triggers", "manual")
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, triggerURL, http.NoBody)
@@ -100,9 +100,11 @@ func (c apiClient) Scan(folder, path string) error {
 
 	q := url.Values{}
 
-	if path == "" {
+	if len(paths) == 0 {
 		q.Add("dir", folder)
-	} else {
+	}
+
+	for _, path := range paths {
 		q.Add("path", path)
 	}
 
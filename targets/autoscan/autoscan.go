@@ -11,14 +11,18 @@ import (
 
 // Config holds configuration for the autoscan target.
 type Config struct {
+	Name      string             `yaml:"name"`
 	URL       string             `yaml:"url"`
 	User      string             `yaml:"username"`
 	Pass      string             `yaml:"password"` //nolint:gosec // user-provided credential, not a hardcoded secret
 	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
 	Verbosity string             `yaml:"verbosity"`
+
+	Limits autoscan.TargetLimits `yaml:",inline"`
 }
 
 type target struct {
+	name string
 	url  string
 	user string
 	pass string
@@ -40,6 +44,7 @@ func New(cfg Config) (autoscan.Target, error) {
 	}
 
 	return &target{
+		name: cfg.Name,
 		url:  cfg.URL,
 		user: cfg.User,
 		pass: cfg.Pass,
@@ -50,23 +55,32 @@ func New(cfg Config) (autoscan.Target, error) {
 	}, nil
 }
 
+func (t target) ID() string {
+	if t.name != "" {
+		return "autoscan:" + t.name
+	}
+
+	return "autoscan:" + t.url
+}
+
 func (t target) Scan(scan autoscan.Scan) error {
 	scanFolder := t.rewrite(scan.Folder)
 
-	scanPath := ""
-	if scan.RelativePath != "" {
-		scanPath = path.Join(scan.Folder, scan.RelativePath)
+	relativePaths := scan.Paths()
+	scanPaths := make([]string, len(relativePaths))
+	for i, relativePath := range relativePaths {
+		scanPaths[i] = path.Join(scan.Folder, relativePath)
 	}
 
 	// send scan request
 	logger := t.log.With().
 		Str("folder", scanFolder).
-		Str("path", scanPath).
+		Strs("paths", scanPaths).
 		Logger()
 
 	logger.Debug().Msg("Scan Sending")
 
-	if err := t.api.Scan(scanFolder, scanPath); err != nil {
+	if err := t.api.Scan(scanFolder, scanPaths); err != nil {
 		return err
 	}
 
@@ -140,19 +140,21 @@ type scanRequest struct {
 	UpdateType string `json:"updateType"`
 }
 
-func (c apiClient) Scan(path string) error {
+func (c apiClient) Scan(paths ...string) error {
 	// create request payload
 	type Payload struct {
 		Updates []scanRequest `json:"Updates"`
 	}
 
 	payload := &Payload{
-		Updates: []scanRequest{
-			{
-				Path:       path,
-				UpdateType: "Modified",
-			},
-		},
+		Updates: make([]scanRequest, 0, len(paths)),
+	}
+
+	for _, path := range paths {
+		payload.Updates = append(payload.Updates, scanRequest{
+			Path:       path,
+			UpdateType: "Modified",
+		})
 	}
 
 	b, err := json.Marshal(payload) //nolint:errchkjson // no interface{} fields; Marshal never errors here
@@ -12,16 +12,22 @@ import (
 
 // Config holds configuration for the Jellyfin target.
 type Config struct {
+	Name      string             `yaml:"name"`
 	URL       string             `yaml:"url"`
 	Token     string             `yaml:"token"`
 	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
 	Verbosity string             `yaml:"verbosity"`
+	Coalesce  bool               `yaml:"coalesce"`
+
+	Limits autoscan.TargetLimits `yaml:",inline"`
 }
 
 type target struct {
+	name      string
 	url       string
 	token     string
 	libraries []library
+	coalesce  bool
 
 	log     zerolog.Logger
 	rewrite autoscan.Rewriter
@@ -52,9 +58,11 @@ func New(cfg Config) (autoscan.Target, error) {
 		Msg("Libraries Retrieved")
 
 	return &target{
+		name:      cfg.Name,
 		url:       cfg.URL,
 		token:     cfg.Token,
 		libraries: libraries,
+		coalesce:  cfg.Coalesce,
 
 		log:     logger,
 		rewrite: rewriter,
@@ -62,6 +70,18 @@ func New(cfg Config) (autoscan.Target, error) {
 	}, nil
 }
 
+func (t target) ID() string {
+	if t.name != "" {
+		return "jellyfin:" + t.name
+	}
+
+	return "jellyfin:" + t.url
+}
+
+func (t target) Coalesce() bool {
+	return t.coalesce
+}
+
 func (t target) Available() error {
 	return t.api.Available()
 }
@@ -76,20 +96,23 @@ func (t target) Scan(scan autoscan.Scan) error {
 		return fmt.Errorf("%w: %s", autoscan.ErrLibraryNotMatched, scanFolder)
 	}
 
-	scanPath := scanFolder
-	if scan.RelativePath != "" {
-		scanPath = path.Join(scanFolder, scan.RelativePath)
+	scanPaths := []string{scanFolder}
+	if relativePaths := scan.Paths(); len(relativePaths) > 0 {
+		scanPaths = make([]string, len(relativePaths))
+		for i, relativePath := range relativePaths {
+			scanPaths[i] = path.Join(scanFolder, relativePath)
+		}
 	}
 
 	logger := t.log.With().
-		Str("path", scanPath).
+		Strs("paths", scanPaths).
 		Str("library", lib.Name).
 		Logger()
 
 	// send scan request
 	logger.Debug().Msg("Scan Sending")
 
-	if err := t.api.Scan(scanPath); err != nil {
+	if err := t.api.Scan(scanPaths...); err != nil {
 		return err
 	}
 
@@ -0,0 +1,212 @@
+// Package kavita provides an autoscan target for Kavita reading servers.
+package kavita
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/httpclient"
+)
+
+type apiClient struct {
+	client  *http.Client
+	log     zerolog.Logger
+	baseURL string
+	token   string
+}
+
+func newAPIClient(baseURL, token string, log zerolog.Logger) apiClient {
+	return apiClient{
+		client:  httpclient.New(),
+		log:     log,
+		baseURL: baseURL,
+		token:   token,
+	}
+}
+
+func (c apiClient) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.client.Do(req) //nolint:gosec // URL is user-configured in app config, SSRF is intentional
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, autoscan.ErrTargetUnavailable)
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		res.Body = autoscan.LimitReadCloser(res.Body)
+		return res, nil
+	}
+
+	c.log.Trace().
+		Stringer("request_url", res.Request.URL).
+		Int("response_status", res.StatusCode).
+		Msg("Request failed")
+
+	// statusCode not in the 2xx range, close response
+	_ = res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("invalid kavita token: %s: %w", res.Status, autoscan.ErrFatal)
+	case http.StatusNotFound,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrTargetUnavailable)
+	default:
+		return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrFatal)
+	}
+}
+
+func (c apiClient) Available() error {
+	// create request
+	reqURL := autoscan.JoinURL(c.baseURL, "api", "health")
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, reqURL, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed creating availability request: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("availability: %w", err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+	return nil
+}
+
+// authenticate exchanges the API key for a JWT, which most endpoints require.
+func (c apiClient) authenticate() (string, error) {
+	// create request
+	q := url.Values{}
+	q.Set("apiKey", c.token)
+	q.Set("pluginName", "autoscan")
+
+	reqURL := autoscan.JoinURL(c.baseURL, "api", "Plugin", "authenticate") + "?" + q.Encode()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, reqURL, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("failed creating authentication request: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("authentication: %w", err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	// decode response
+	resp := new(struct {
+		Token string `json:"token"`
+	})
+
+	if err := json.NewDecoder(res.Body).Decode(resp); err != nil {
+		return "", fmt.Errorf("failed decoding authentication request response: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	return resp.Token, nil
+}
+
+type library struct {
+	ID   int
+	Name string
+	Path string
+}
+
+func (c apiClient) Libraries() ([]library, error) {
+	jwt, err := c.authenticate()
+	if err != nil {
+		return nil, err
+	}
+
+	// create request
+	reqURL := autoscan.JoinURL(c.baseURL, "api", "Library", "libraries")
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, reqURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating libraries request: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("libraries: %w", err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	// decode response
+	type Response struct {
+		ID      int      `json:"id"`
+		Name    string   `json:"name"`
+		Folders []string `json:"folders"`
+	}
+
+	resp := make([]Response, 0)
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed decoding libraries request response: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	// process response
+	libraries := make([]library, 0)
+	for _, lib := range resp {
+		for _, folder := range lib.Folders {
+			// Add trailing slash if there is none.
+			if folder != "" && folder[len(folder)-1] != '/' {
+				folder += "/"
+			}
+
+			libraries = append(libraries, library{
+				ID:   lib.ID,
+				Name: lib.Name,
+				Path: folder,
+			})
+		}
+	}
+
+	return libraries, nil
+}
+
+// Scan scans the series within the folder.
+// Kavita scans the whole library when the folder does not belong to a series.
+func (c apiClient) Scan(folder string) error {
+	// create request payload
+	type Payload struct {
+		APIKey     string `json:"apiKey"`
+		FolderPath string `json:"folderPath"`
+	}
+
+	b, err := json.Marshal(Payload{APIKey: c.token, FolderPath: folder}) //nolint:errchkjson // no interface{} fields; Marshal never errors here
+	if err != nil {
+		return fmt.Errorf("failed encoding scan request payload: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	// create request
+	reqURL := autoscan.JoinURL(c.baseURL, "api", "Library", "scan-folder")
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, reqURL, bytes.NewBuffer(b))
+	if err != nil {
+		return fmt.Errorf("failed creating scan request: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("scan: %w", err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+	return nil
+}
@@ -0,0 +1,126 @@
+package kavita
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// Config holds configuration for the Kavita target.
+type Config struct {
+	Name      string             `yaml:"name"`
+	URL       string             `yaml:"url"`
+	Token     string             `yaml:"token"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Verbosity string             `yaml:"verbosity"`
+	Coalesce  bool               `yaml:"coalesce"`
+
+	Limits autoscan.TargetLimits `yaml:",inline"`
+}
+
+type target struct {
+	name      string
+	url       string
+	token     string
+	libraries []library
+	coalesce  bool
+
+	log     zerolog.Logger
+	rewrite autoscan.Rewriter
+	api     apiClient
+}
+
+// New creates a Kavita target from the given Config.
+func New(cfg Config) (autoscan.Target, error) {
+	logger := autoscan.GetLogger(cfg.Verbosity).With().
+		Str("target", "kavita").
+		Str("url", cfg.URL).
+		Logger()
+
+	rewriter, err := autoscan.NewRewriter(cfg.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("create rewriter: %w", err)
+	}
+
+	api := newAPIClient(cfg.URL, cfg.Token, logger)
+
+	libraries, err := api.Libraries()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug().
+		Interface("libraries", libraries).
+		Msg("Libraries Retrieved")
+
+	return &target{
+		name:      cfg.Name,
+		url:       cfg.URL,
+		token:     cfg.Token,
+		libraries: libraries,
+		coalesce:  cfg.Coalesce,
+
+		log:     logger,
+		rewrite: rewriter,
+		api:     api,
+	}, nil
+}
+
+func (t target) ID() string {
+	if t.name != "" {
+		return "kavita:" + t.name
+	}
+
+	return "kavita:" + t.url
+}
+
+func (t target) Coalesce() bool {
+	return t.coalesce
+}
+
+func (t target) Available() error {
+	return t.api.Available()
+}
+
+func (t target) Scan(scan autoscan.Scan) error {
+	// determine library for this scan
+	scanFolder := t.rewrite(scan.Folder)
+
+	lib, err := t.getScanLibrary(scanFolder)
+	if err != nil {
+		t.log.Debug().Str("folder", scanFolder).Msg("Library Not Matched")
+		return fmt.Errorf("%w: %s", autoscan.ErrLibraryNotMatched, scanFolder)
+	}
+
+	logger := t.log.With().
+		Str("path", scanFolder).
+		Str("library", lib.Name).
+		Logger()
+
+	// send scan request
+	logger.Debug().Msg("Scan Sending")
+
+	if err := t.api.Scan(scanFolder); err != nil {
+		return err
+	}
+
+	logger.Info().Msg("Scan Sent")
+	return nil
+}
+
+func (t target) getScanLibrary(folder string) (*library, error) {
+	for _, l := range t.libraries {
+		if strings.HasPrefix(folder, l.Path) {
+			return &l, nil
+		}
+		// Library root path
+		if autoscan.CleanedPathEqual(folder, l.Path) {
+			return &l, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%v: failed determining library", folder)
+}
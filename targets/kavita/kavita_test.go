@@ -0,0 +1,132 @@
+package kavita
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/cloudbox/autoscan"
+)
+
+const librariesResponse = `[
+	{"id": 1, "name": "Comics", "folders": ["/comics", "/graphic-novels/"]},
+	{"id": 2, "name": "Manga", "folders": ["/manga"]}
+]`
+
+// newServer returns a Kavita server which records the folders it scans.
+func newServer(t *testing.T, scanned *[]string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/health":
+		case r.Method == http.MethodPost && r.URL.Path == "/api/Plugin/authenticate":
+			if r.URL.Query().Get("apiKey") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"username": "admin", "token": "jwt"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/Library/libraries":
+			if r.Header.Get("Authorization") != "Bearer jwt" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			_, _ = w.Write([]byte(librariesResponse))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/Library/scan-folder":
+			var payload struct {
+				APIKey     string `json:"apiKey"`
+				FolderPath string `json:"folderPath"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.APIKey != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			*scanned = append(*scanned, payload.FolderPath)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestScan(t *testing.T) {
+	type Test struct {
+		Name     string
+		Folder   string
+		Expected []string
+		Err      error
+	}
+
+	testCases := []Test{
+		{
+			Name:     "Series",
+			Folder:   "/mnt/unionfs/Media/comics/Saga",
+			Expected: []string{"/comics/Saga"},
+		},
+		{
+			Name:     "Second folder of a library",
+			Folder:   "/mnt/unionfs/Media/graphic-novels/Maus",
+			Expected: []string{"/graphic-novels/Maus"},
+		},
+		{
+			Name:     "Library root",
+			Folder:   "/mnt/unionfs/Media/manga",
+			Expected: []string{"/manga"},
+		},
+		{
+			Name:   "Library not matched",
+			Folder: "/mnt/unionfs/Media/books/Dune",
+			Err:    autoscan.ErrLibraryNotMatched,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var scanned []string
+			server := newServer(t, &scanned)
+			defer server.Close()
+
+			target, err := New(Config{
+				URL:   server.URL,
+				Token: "secret",
+				Rewrite: []autoscan.Rewrite{{
+					From: "^/mnt/unionfs/Media/",
+					To:   "/",
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := target.Available(); err != nil {
+				t.Fatalf("expected target to be available: %v", err)
+			}
+
+			err = target.Scan(autoscan.Scan{Folder: tc.Folder})
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error %v, got %v", tc.Err, err)
+			}
+
+			if !reflect.DeepEqual(tc.Expected, scanned) {
+				t.Errorf("expected scans %v, got %v", tc.Expected, scanned)
+			}
+		})
+	}
+}
+
+func TestNewInvalidToken(t *testing.T) {
+	var scanned []string
+	server := newServer(t, &scanned)
+	defer server.Close()
+
+	_, err := New(Config{URL: server.URL, Token: "wrong"})
+	if !errors.Is(err, autoscan.ErrFatal) {
+		t.Errorf("expected ErrFatal, got %v", err)
+	}
+}
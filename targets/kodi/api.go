@@ -0,0 +1,221 @@
+// Package kodi provides an autoscan target for Kodi media centers via JSON-RPC.
+package kodi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/httpclient"
+)
+
+type apiClient struct {
+	client  *http.Client
+	log     zerolog.Logger
+	baseURL string
+	user    string
+	pass    string
+}
+
+func newAPIClient(baseURL, user, pass string, log zerolog.Logger) apiClient {
+	return apiClient{
+		client:  httpclient.New(),
+		log:     log,
+		baseURL: baseURL,
+		user:    user,
+		pass:    pass,
+	}
+}
+
+func (c apiClient) do(req *http.Request) (*http.Response, error) {
+	if c.user != "" || c.pass != "" {
+		req.SetBasicAuth(c.user, c.pass)
+	}
+
+	res, err := c.client.Do(req) //nolint:gosec // URL is user-configured in app config, SSRF is intentional
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, autoscan.ErrTargetUnavailable)
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		res.Body = autoscan.LimitReadCloser(res.Body)
+		return res, nil
+	}
+
+	c.log.Trace().
+		Stringer("request_url", res.Request.URL).
+		Int("response_status", res.StatusCode).
+		Msg("Request failed")
+
+	// statusCode not in the 2xx range, close response
+	_ = res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("invalid kodi credentials: %s: %w", res.Status, autoscan.ErrFatal)
+	case http.StatusNotFound,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrTargetUnavailable)
+	default:
+		return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrFatal)
+	}
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// call invokes the JSON-RPC method and decodes its result into result.
+func (c apiClient) call(method string, params, result any) error {
+	type Request struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params,omitempty"`
+		ID      int    `json:"id"`
+	}
+
+	b, err := json.Marshal(Request{JSONRPC: "2.0", Method: method, Params: params, ID: 1})
+	if err != nil {
+		return fmt.Errorf("failed encoding %s request payload: %w: %w", method, err, autoscan.ErrFatal)
+	}
+
+	// create request
+	reqURL := autoscan.JoinURL(c.baseURL, "jsonrpc")
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, reqURL, bytes.NewBuffer(b))
+	if err != nil {
+		return fmt.Errorf("failed creating %s request: %w: %w", method, err, autoscan.ErrFatal)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	// decode response
+	resp := new(struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	})
+
+	if err := json.NewDecoder(res.Body).Decode(resp); err != nil {
+		return fmt.Errorf("failed decoding %s request response: %w: %w", method, err, autoscan.ErrFatal)
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("%s: %s (%d): %w", method, resp.Error.Message, resp.Error.Code, autoscan.ErrFatal)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed decoding %s result: %w: %w", method, err, autoscan.ErrFatal)
+	}
+
+	return nil
+}
+
+func (c apiClient) Available() error {
+	var pong string
+	if err := c.call("JSONRPC.Ping", nil, &pong); err != nil {
+		return fmt.Errorf("availability: %w", err)
+	}
+
+	return nil
+}
+
+type library struct {
+	Name  string
+	Path  string
+	Media string // video or music
+}
+
+// Libraries returns the sources of the given media type.
+// The paths of multipath sources are returned as separate libraries.
+func (c apiClient) Libraries(media string) ([]library, error) {
+	type Response struct {
+		Sources []struct {
+			File  string `json:"file"`
+			Label string `json:"label"`
+		} `json:"sources"`
+	}
+
+	resp := new(Response)
+	if err := c.call("Files.GetSources", map[string]string{"media": media}, resp); err != nil {
+		return nil, fmt.Errorf("libraries: %w", err)
+	}
+
+	// process response
+	libraries := make([]library, 0)
+	for _, source := range resp.Sources {
+		for _, folder := range sourcePaths(source.File) {
+			// Add trailing slash if there is none.
+			if folder != "" && folder[len(folder)-1] != '/' {
+				folder += "/"
+			}
+
+			libraries = append(libraries, library{
+				Name:  source.Label,
+				Path:  folder,
+				Media: media,
+			})
+		}
+	}
+
+	return libraries, nil
+}
+
+// sourcePaths returns the paths of a source.
+// A multipath source holds the URL-encoded paths of the source, separated by slashes.
+func sourcePaths(file string) []string {
+	encoded, ok := strings.CutPrefix(file, "multipath://")
+	if !ok {
+		return []string{file}
+	}
+
+	var paths []string
+	for _, p := range strings.Split(encoded, "/") {
+		if decoded, err := url.PathUnescape(p); err == nil && decoded != "" {
+			paths = append(paths, decoded)
+		}
+	}
+
+	return paths
+}
+
+// Scan scans the directory with the library of the given media type.
+func (c apiClient) Scan(directory, media string) error {
+	method := "VideoLibrary.Scan"
+	if media == "music" {
+		method = "AudioLibrary.Scan"
+	}
+
+	params := map[string]any{
+		"directory":   directory,
+		"showdialogs": false,
+	}
+
+	var result string
+	if err := c.call(method, params, &result); err != nil {
+		return fmt.Errorf("scan: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package kodi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// Config holds configuration for the Kodi target.
+type Config struct {
+	Name      string             `yaml:"name"`
+	URL       string             `yaml:"url"`
+	Username  string             `yaml:"username"`
+	Password  string             `yaml:"password"` //nolint:gosec // user-provided credential field
+	Music     bool               `yaml:"music"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Verbosity string             `yaml:"verbosity"`
+	Coalesce  bool               `yaml:"coalesce"`
+
+	Limits autoscan.TargetLimits `yaml:",inline"`
+}
+
+type target struct {
+	name      string
+	url       string
+	libraries []library
+	coalesce  bool
+
+	log     zerolog.Logger
+	rewrite autoscan.Rewriter
+	api     apiClient
+}
+
+// New creates a Kodi target from the given Config.
+func New(cfg Config) (autoscan.Target, error) {
+	logger := autoscan.GetLogger(cfg.Verbosity).With().
+		Str("target", "kodi").
+		Str("url", cfg.URL).
+		Logger()
+
+	rewriter, err := autoscan.NewRewriter(cfg.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("create rewriter: %w", err)
+	}
+
+	api := newAPIClient(cfg.URL, cfg.Username, cfg.Password, logger)
+
+	libraries, err := api.Libraries("video")
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Music {
+		music, err := api.Libraries("music")
+		if err != nil {
+			return nil, err
+		}
+
+		libraries = append(libraries, music...)
+	}
+
+	logger.Debug().
+		Interface("libraries", libraries).
+		Msg("Libraries Retrieved")
+
+	return &target{
+		name:      cfg.Name,
+		url:       cfg.URL,
+		libraries: libraries,
+		coalesce:  cfg.Coalesce,
+
+		log:     logger,
+		rewrite: rewriter,
+		api:     api,
+	}, nil
+}
+
+func (t target) ID() string {
+	if t.name != "" {
+		return "kodi:" + t.name
+	}
+
+	return "kodi:" + t.url
+}
+
+func (t target) Coalesce() bool {
+	return t.coalesce
+}
+
+func (t target) Available() error {
+	return t.api.Available()
+}
+
+func (t target) Scan(scan autoscan.Scan) error {
+	// determine library for this scan
+	scanFolder := t.rewrite(scan.Folder)
+
+	libs, err := t.getScanLibrary(scanFolder)
+	if err != nil {
+		t.log.Debug().Str("folder", scanFolder).Msg("Library Not Matched")
+		return fmt.Errorf("%w: %s", autoscan.ErrLibraryNotMatched, scanFolder)
+	}
+
+	// Kodi only scans directories, which must end with a slash.
+	directory := strings.TrimSuffix(scanFolder, "/") + "/"
+
+	// send a scan request per media type
+	scanned := make(map[string]bool)
+	for _, lib := range libs {
+		if scanned[lib.Media] {
+			continue
+		}
+
+		logger := t.log.With().
+			Str("path", directory).
+			Str("library", lib.Name).
+			Str("media", lib.Media).
+			Logger()
+
+		logger.Debug().Msg("Scan Sending")
+
+		if err := t.api.Scan(directory, lib.Media); err != nil {
+			return err
+		}
+
+		scanned[lib.Media] = true
+		logger.Info().Msg("Scan Sent")
+	}
+
+	return nil
+}
+
+func (t target) getScanLibrary(folder string) ([]library, error) {
+	libraries := make([]library, 0)
+
+	for _, l := range t.libraries {
+		if strings.HasPrefix(folder, l.Path) || autoscan.CleanedPathEqual(folder, l.Path) {
+			libraries = append(libraries, l)
+		}
+	}
+
+	if len(libraries) == 0 {
+		return nil, fmt.Errorf("%v: failed determining libraries", folder)
+	}
+
+	return libraries, nil
+}
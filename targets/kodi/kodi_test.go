@@ -0,0 +1,155 @@
+package kodi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/cloudbox/autoscan"
+)
+
+type rpcCall struct {
+	Method    string
+	Directory string
+}
+
+// newServer returns a Kodi JSON-RPC server which records the scans it receives.
+func newServer(t *testing.T, calls *[]rpcCall) *httptest.Server {
+	t.Helper()
+
+	sources := map[string][]map[string]string{
+		"video": {
+			{"label": "Movies", "file": "/data/Movies/"},
+			{"label": "TV", "file": "multipath://%2fdata%2fTV%2f/%2fdata%2fAnime/"},
+		},
+		"music": {
+			{"label": "Music", "file": "/data/Music"},
+		},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); r.URL.Path != "/jsonrpc" || user != "kodi" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req struct {
+			Method string         `json:"method"`
+			Params map[string]any `json:"params"`
+			ID     int            `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+
+		var result any
+		switch req.Method {
+		case "JSONRPC.Ping":
+			result = "pong"
+		case "Files.GetSources":
+			result = map[string]any{"sources": sources[req.Params["media"].(string)]}
+		case "VideoLibrary.Scan", "AudioLibrary.Scan":
+			if req.Params["directory"] == "/data/Movies/Broken/" {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"id":    req.ID,
+					"error": map[string]any{"code": -32602, "message": "Invalid params."},
+				})
+				return
+			}
+
+			*calls = append(*calls, rpcCall{Method: req.Method, Directory: req.Params["directory"].(string)})
+			result = "OK"
+		default:
+			t.Errorf("unexpected method: %s", req.Method)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "result": result})
+	}))
+}
+
+func TestScan(t *testing.T) {
+	type Test struct {
+		Name     string
+		Folder   string
+		Expected []rpcCall
+		Err      error
+	}
+
+	testCases := []Test{
+		{
+			Name:     "Video library",
+			Folder:   "/mnt/unionfs/Media/Movies/Tenet (2020)",
+			Expected: []rpcCall{{Method: "VideoLibrary.Scan", Directory: "/data/Movies/Tenet (2020)/"}},
+		},
+		{
+			Name:     "Path of a multipath source",
+			Folder:   "/mnt/unionfs/Media/Anime/Naruto",
+			Expected: []rpcCall{{Method: "VideoLibrary.Scan", Directory: "/data/Anime/Naruto/"}},
+		},
+		{
+			Name:     "Music library",
+			Folder:   "/mnt/unionfs/Media/Music/Daft Punk",
+			Expected: []rpcCall{{Method: "AudioLibrary.Scan", Directory: "/data/Music/Daft Punk/"}},
+		},
+		{
+			Name:   "Library not matched",
+			Folder: "/mnt/unionfs/Media/Books/Dune",
+			Err:    autoscan.ErrLibraryNotMatched,
+		},
+		{
+			Name:   "JSON-RPC error",
+			Folder: "/mnt/unionfs/Media/Movies/Broken",
+			Err:    autoscan.ErrFatal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var calls []rpcCall
+			server := newServer(t, &calls)
+			defer server.Close()
+
+			target, err := New(Config{
+				URL:      server.URL,
+				Username: "kodi",
+				Password: "secret",
+				Music:    true,
+				Rewrite: []autoscan.Rewrite{{
+					From: "^/mnt/unionfs/Media/",
+					To:   "/data/",
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := target.Available(); err != nil {
+				t.Fatalf("expected target to be available: %v", err)
+			}
+
+			err = target.Scan(autoscan.Scan{Folder: tc.Folder})
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error %v, got %v", tc.Err, err)
+			}
+
+			if !reflect.DeepEqual(tc.Expected, calls) {
+				t.Errorf("expected calls %v, got %v", tc.Expected, calls)
+			}
+		})
+	}
+}
+
+func TestNewInvalidCredentials(t *testing.T) {
+	var calls []rpcCall
+	server := newServer(t, &calls)
+	defer server.Close()
+
+	_, err := New(Config{URL: server.URL, Username: "kodi", Password: "wrong"})
+	if !errors.Is(err, autoscan.ErrFatal) {
+		t.Errorf("expected ErrFatal, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package komga
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+)
+
+const librariesResponse = `[
+	{"id": "0B7JR3VRCQ5RN", "name": "Comics", "root": "/comics"},
+	{"id": "0B7JR3VRCQ5RP", "name": "Manga", "root": "/manga/"}
+]`
+
+// newServer returns a Komga server which records the libraries it scans.
+func newServer(t *testing.T, scanned *[]string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/users/me":
+			_, _ = w.Write([]byte(`{"id": "0B7JR3VRCQ5RQ"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/libraries":
+			_, _ = w.Write([]byte(librariesResponse))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/libraries/0B7JR3VRCQ5RN/scan",
+			r.Method == http.MethodPost && r.URL.Path == "/api/v1/libraries/0B7JR3VRCQ5RP/scan":
+			*scanned = append(*scanned, r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestScan(t *testing.T) {
+	type Test struct {
+		Name     string
+		Folder   string
+		Expected []string
+		Err      error
+	}
+
+	testCases := []Test{
+		{
+			Name:     "Series",
+			Folder:   "/mnt/unionfs/Media/comics/Saga",
+			Expected: []string{"/api/v1/libraries/0B7JR3VRCQ5RN/scan"},
+		},
+		{
+			Name:     "Library root",
+			Folder:   "/mnt/unionfs/Media/manga",
+			Expected: []string{"/api/v1/libraries/0B7JR3VRCQ5RP/scan"},
+		},
+		{
+			Name:   "Library not matched",
+			Folder: "/mnt/unionfs/Media/comics-archive/Saga",
+			Err:    autoscan.ErrLibraryNotMatched,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var scanned []string
+			server := newServer(t, &scanned)
+			defer server.Close()
+
+			target, err := New(Config{
+				URL:   server.URL,
+				Token: "secret",
+				Rewrite: []autoscan.Rewrite{{
+					From: "^/mnt/unionfs/Media/",
+					To:   "/",
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := target.Available(); err != nil {
+				t.Fatalf("expected target to be available: %v", err)
+			}
+
+			err = target.Scan(autoscan.Scan{Folder: tc.Folder})
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error %v, got %v", tc.Err, err)
+			}
+
+			if !reflect.DeepEqual(tc.Expected, scanned) {
+				t.Errorf("expected scans %v, got %v", tc.Expected, scanned)
+			}
+		})
+	}
+}
+
+func TestNewInvalidToken(t *testing.T) {
+	var scanned []string
+	server := newServer(t, &scanned)
+	defer server.Close()
+
+	_, err := New(Config{URL: server.URL, Token: "wrong"})
+	if !errors.Is(err, autoscan.ErrFatal) {
+		t.Errorf("expected ErrFatal, got %v", err)
+	}
+}
+
+func TestScanCoveredByLibraryScan(t *testing.T) {
+	var scanned []string
+	server := newServer(t, &scanned)
+	defer server.Close()
+
+	target, err := New(Config{
+		URL:   server.URL,
+		Token: "secret",
+		Rewrite: []autoscan.Rewrite{{
+			From: "^/mnt/unionfs/Media/",
+			To:   "/",
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := time.Now().Add(-1 * time.Minute).Unix()
+	scans := []autoscan.Scan{
+		{Folder: "/mnt/unionfs/Media/comics/Saga", Time: changed},
+		// changed before the first library scan was sent
+		{Folder: "/mnt/unionfs/Media/comics/Monstress", Time: changed},
+		{Folder: "/mnt/unionfs/Media/manga/Berserk", Time: changed},
+		// changed after the first library scan was sent
+		{Folder: "/mnt/unionfs/Media/comics/Monstress", Time: time.Now().Add(time.Minute).Unix()},
+	}
+	for _, scan := range scans {
+		if err := target.Scan(scan); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{
+		"/api/v1/libraries/0B7JR3VRCQ5RN/scan",
+		"/api/v1/libraries/0B7JR3VRCQ5RP/scan",
+		"/api/v1/libraries/0B7JR3VRCQ5RN/scan",
+	}
+	if !reflect.DeepEqual(expected, scanned) {
+		t.Errorf("expected scans %v, got %v", expected, scanned)
+	}
+}
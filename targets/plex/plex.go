@@ -12,16 +12,22 @@ import (
 
 // Config holds configuration for the Plex target.
 type Config struct {
+	Name      string             `yaml:"name"`
 	URL       string             `yaml:"url"`
 	Token     string             `yaml:"token"`
 	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
 	Verbosity string             `yaml:"verbosity"`
+	Coalesce  bool               `yaml:"coalesce"`
+
+	Limits autoscan.TargetLimits `yaml:",inline"`
 }
 
 type target struct {
+	name      string
 	url       string
 	token     string
 	libraries []library
+	coalesce  bool
 
 	log     zerolog.Logger
 	rewrite autoscan.Rewriter
@@ -61,9 +67,11 @@ func New(cfg Config) (autoscan.Target, error) {
 		Msg("Libraries Retrieved")
 
 	return &target{
+		name:      cfg.Name,
 		url:       cfg.URL,
 		token:     cfg.Token,
 		libraries: libraries,
+		coalesce:  cfg.Coalesce,
 
 		log:     logger,
 		rewrite: rewriter,
@@ -71,6 +79,18 @@ func New(cfg Config) (autoscan.Target, error) {
 	}, nil
 }
 
+func (t target) ID() string {
+	if t.name != "" {
+		return "plex:" + t.name
+	}
+
+	return "plex:" + t.url
+}
+
+func (t target) Coalesce() bool {
+	return t.coalesce
+}
+
 func (t target) Available() error {
 	_, err := t.api.Version()
 	return err
@@ -0,0 +1,178 @@
+// Package subsonic provides an autoscan target for servers implementing the
+// Subsonic API, such as Navidrome.
+package subsonic
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // the Subsonic API mandates MD5 tokens
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/httpclient"
+)
+
+const (
+	apiVersion = "1.16.1"
+	clientName = "autoscan"
+)
+
+type apiClient struct {
+	client  *http.Client
+	log     zerolog.Logger
+	baseURL string
+	user    string
+	pass    string
+}
+
+func newAPIClient(baseURL, user, pass string, log zerolog.Logger) apiClient {
+	return apiClient{
+		client:  httpclient.New(),
+		log:     log,
+		baseURL: baseURL,
+		user:    user,
+		pass:    pass,
+	}
+}
+
+func (c apiClient) do(req *http.Request) (*http.Response, error) {
+	res, err := c.client.Do(req) //nolint:gosec // URL is user-configured in app config, SSRF is intentional
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, autoscan.ErrTargetUnavailable)
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		res.Body = autoscan.LimitReadCloser(res.Body)
+		return res, nil
+	}
+
+	c.log.Trace().
+		Stringer("request_url", res.Request.URL).
+		Int("response_status", res.StatusCode).
+		Msg("Request failed")
+
+	// statusCode not in the 2xx range, close response
+	_ = res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("invalid subsonic credentials: %s: %w", res.Status, autoscan.ErrFatal)
+	case http.StatusNotFound,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrTargetUnavailable)
+	default:
+		return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrFatal)
+	}
+}
+
+type scanStatus struct {
+	Scanning bool `json:"scanning"`
+	Count    int  `json:"count"`
+}
+
+type response struct {
+	Status string `json:"status"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	ScanStatus *scanStatus `json:"scanStatus"`
+}
+
+// call invokes the method with token authentication, using a new salt for every request.
+func (c apiClient) call(method string) (*response, error) {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed generating %s salt: %w: %w", method, err, autoscan.ErrFatal)
+	}
+
+	s := hex.EncodeToString(salt)
+	token := md5.Sum([]byte(c.pass + s)) //nolint:gosec // the Subsonic API mandates MD5 tokens
+
+	q := url.Values{}
+	q.Set("u", c.user)
+	q.Set("t", hex.EncodeToString(token[:]))
+	q.Set("s", s)
+	q.Set("v", apiVersion)
+	q.Set("c", clientName)
+	q.Set("f", "json")
+
+	// create request
+	reqURL := autoscan.JoinURL(c.baseURL, "rest", method) + "?" + q.Encode()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, reqURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating %s request: %w: %w", method, err, autoscan.ErrFatal)
+	}
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	// decode response
+	resp := new(struct {
+		Response response `json:"subsonic-response"`
+	})
+
+	if err := json.NewDecoder(res.Body).Decode(resp); err != nil {
+		return nil, fmt.Errorf("failed decoding %s request response: %w: %w", method, err, autoscan.ErrFatal)
+	}
+
+	if resp.Response.Status == "ok" {
+		return &resp.Response, nil
+	}
+
+	if resp.Response.Error == nil {
+		return nil, fmt.Errorf("%s: status %q: %w", method, resp.Response.Status, autoscan.ErrFatal)
+	}
+
+	switch resp.Response.Error.Code {
+	case 40, 41, 50:
+		// wrong credentials, token authentication not supported, or not an admin
+		return nil, fmt.Errorf("invalid subsonic credentials: %s: %w", resp.Response.Error.Message, autoscan.ErrFatal)
+	default:
+		return nil, fmt.Errorf("%s: %s (%d): %w", method, resp.Response.Error.Message, resp.Response.Error.Code, autoscan.ErrFatal)
+	}
+}
+
+func (c apiClient) Available() error {
+	if _, err := c.call("ping"); err != nil {
+		return fmt.Errorf("availability: %w", err)
+	}
+
+	return nil
+}
+
+func (c apiClient) ScanStatus() (*scanStatus, error) {
+	resp, err := c.call("getScanStatus")
+	if err != nil {
+		return nil, fmt.Errorf("scan status: %w", err)
+	}
+
+	if resp.ScanStatus == nil {
+		return nil, fmt.Errorf("scan status: missing in response: %w", autoscan.ErrFatal)
+	}
+
+	return resp.ScanStatus, nil
+}
+
+// Scan starts a scan of all music folders.
+func (c apiClient) Scan() error {
+	if _, err := c.call("startScan"); err != nil {
+		return fmt.Errorf("scan: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package subsonic
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// Config holds configuration for the Subsonic target.
+type Config struct {
+	Name      string             `yaml:"name"`
+	URL       string             `yaml:"url"`
+	Username  string             `yaml:"username"`
+	Password  string             `yaml:"password"` //nolint:gosec // user-provided credential field
+	Folders   []string           `yaml:"folders"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Verbosity string             `yaml:"verbosity"`
+
+	Limits autoscan.TargetLimits `yaml:",inline"`
+}
+
+type target struct {
+	name    string
+	url     string
+	folders []string
+
+	// scanned holds the unix time of the last scan sent to the server
+	scanned   *int64
+	scannedMu *sync.Mutex
+
+	log     zerolog.Logger
+	rewrite autoscan.Rewriter
+	api     apiClient
+}
+
+// New creates a Subsonic target from the given Config.
+func New(cfg Config) (autoscan.Target, error) {
+	logger := autoscan.GetLogger(cfg.Verbosity).With().
+		Str("target", "subsonic").
+		Str("url", cfg.URL).
+		Logger()
+
+	rewriter, err := autoscan.NewRewriter(cfg.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("create rewriter: %w", err)
+	}
+
+	api := newAPIClient(cfg.URL, cfg.Username, cfg.Password, logger)
+
+	// verify the credentials
+	if err := api.Available(); err != nil {
+		return nil, err
+	}
+
+	folders := make([]string, 0, len(cfg.Folders))
+	for _, folder := range cfg.Folders {
+		// Add trailing slash if there is none.
+		if folder != "" && folder[len(folder)-1] != '/' {
+			folder += "/"
+		}
+
+		folders = append(folders, folder)
+	}
+
+	return &target{
+		name:    cfg.Name,
+		url:     cfg.URL,
+		folders: folders,
+
+		scanned:   new(int64),
+		scannedMu: new(sync.Mutex),
+
+		log:     logger,
+		rewrite: rewriter,
+		api:     api,
+	}, nil
+}
+
+func (t target) ID() string {
+	if t.name != "" {
+		return "subsonic:" + t.name
+	}
+
+	return "subsonic:" + t.url
+}
+
+// Coalesce reports that the target accepts coalesced scans,
+// as the server scans all of its music folders.
+func (target) Coalesce() bool {
+	return true
+}
+
+func (t target) Available() error {
+	return t.api.Available()
+}
+
+func (t target) Scan(scan autoscan.Scan) error {
+	scanFolder := t.rewrite(scan.Folder)
+
+	if !t.matchFolder(scanFolder) {
+		t.log.Debug().Str("folder", scanFolder).Msg("Library Not Matched")
+		return fmt.Errorf("%w: %s", autoscan.ErrLibraryNotMatched, scanFolder)
+	}
+
+	logger := t.log.With().
+		Str("path", scanFolder).
+		Logger()
+
+	// a scan of the server sent after the change already picks it up
+	if t.scannedSince(scan.Time) {
+		logger.Debug().Msg("Scan Covered By Server Scan")
+		return nil
+	}
+
+	// a new scan cannot start while the server is scanning,
+	// so hold back the scan until the server is done.
+	status, err := t.api.ScanStatus()
+	if err != nil {
+		return err
+	}
+
+	if status.Scanning {
+		logger.Debug().
+			Int("count", status.Count).
+			Msg("Scan In Progress")
+		return fmt.Errorf("scan in progress: %w", autoscan.ErrTargetBusy)
+	}
+
+	// send scan request
+	logger.Debug().Msg("Scan Sending")
+
+	sent := time.Now().Unix()
+	if err := t.api.Scan(); err != nil {
+		return err
+	}
+
+	t.setScanned(sent)
+
+	logger.Info().Msg("Scan Sent")
+	return nil
+}
+
+// matchFolder reports whether the folder is within one of the configured folders.
+// Every folder matches when no folders are configured.
+func (t target) matchFolder(folder string) bool {
+	if len(t.folders) == 0 {
+		return true
+	}
+
+	for _, f := range t.folders {
+		if strings.HasPrefix(folder, f) || autoscan.CleanedPathEqual(folder, f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scannedSince reports whether a scan of the server was sent after the given unix time.
+func (t target) scannedSince(since int64) bool {
+	t.scannedMu.Lock()
+	defer t.scannedMu.Unlock()
+
+	return since < *t.scanned
+}
+
+func (t target) setScanned(sent int64) {
+	t.scannedMu.Lock()
+	defer t.scannedMu.Unlock()
+
+	*t.scanned = max(*t.scanned, sent)
+}
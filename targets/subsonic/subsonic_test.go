@@ -0,0 +1,162 @@
+package subsonic
+
+import (
+	"crypto/md5" //nolint:gosec // the Subsonic API mandates MD5 tokens
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+)
+
+type server struct {
+	scanning bool
+	scans    int
+}
+
+// newServer returns a Subsonic server which authenticates the user "admin" with the password "secret".
+func newServer(t *testing.T, s *server) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		token := md5.Sum([]byte("secret" + q.Get("s"))) //nolint:gosec // the Subsonic API mandates MD5 tokens
+
+		if q.Get("u") != "admin" || q.Get("t") != hex.EncodeToString(token[:]) || q.Get("f") != "json" {
+			_, _ = w.Write([]byte(`{"subsonic-response": {"status": "failed", "version": "1.16.1",
+				"error": {"code": 40, "message": "Wrong username or password"}}}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/rest/ping":
+			_, _ = w.Write([]byte(`{"subsonic-response": {"status": "ok", "version": "1.16.1"}}`))
+		case "/rest/getScanStatus":
+			_, _ = fmt.Fprintf(w, `{"subsonic-response": {"status": "ok", "version": "1.16.1",
+				"scanStatus": {"scanning": %t, "count": 120}}}`, s.scanning)
+		case "/rest/startScan":
+			s.scans++
+			s.scanning = true
+			_, _ = w.Write([]byte(`{"subsonic-response": {"status": "ok", "version": "1.16.1",
+				"scanStatus": {"scanning": true, "count": 0}}}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestScan(t *testing.T) {
+	type Test struct {
+		Name     string
+		Folder   string
+		Scanning bool
+		Scans    int
+		Err      error
+	}
+
+	testCases := []Test{
+		{
+			Name:   "Scan",
+			Folder: "/mnt/unionfs/Media/Music/Daft Punk",
+			Scans:  1,
+		},
+		{
+			Name:   "Music folder",
+			Folder: "/mnt/unionfs/Media/Music",
+			Scans:  1,
+		},
+		{
+			Name:     "Scan in progress",
+			Folder:   "/mnt/unionfs/Media/Music/Daft Punk",
+			Scanning: true,
+			Err:      autoscan.ErrTargetBusy,
+		},
+		{
+			Name:   "Folder not matched",
+			Folder: "/mnt/unionfs/Media/Movies/Tenet (2020)",
+			Err:    autoscan.ErrLibraryNotMatched,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s := &server{scanning: tc.Scanning}
+			srv := newServer(t, s)
+			defer srv.Close()
+
+			target, err := New(Config{
+				URL:      srv.URL,
+				Username: "admin",
+				Password: "secret",
+				Folders:  []string{"/music"},
+				Rewrite: []autoscan.Rewrite{{
+					From: "^/mnt/unionfs/Media/Music",
+					To:   "/music",
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = target.Scan(autoscan.Scan{Folder: tc.Folder})
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error %v, got %v", tc.Err, err)
+			}
+
+			if s.scans != tc.Scans {
+				t.Errorf("expected %d scans, got %d", tc.Scans, s.scans)
+			}
+		})
+	}
+}
+
+func TestScanCoveredByServerScan(t *testing.T) {
+	s := &server{}
+	srv := newServer(t, s)
+	defer srv.Close()
+
+	target, err := New(Config{URL: srv.URL, Username: "admin", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := time.Now().Add(-1 * time.Minute).Unix()
+	if err := target.Scan(autoscan.Scan{Folder: "/music/Daft Punk", Time: changed}); err != nil {
+		t.Fatal(err)
+	}
+
+	// changed before the scan was sent
+	if err := target.Scan(autoscan.Scan{Folder: "/music/Justice", Time: changed}); err != nil {
+		t.Fatal(err)
+	}
+
+	// changed after the scan was sent, while the server is still scanning
+	later := autoscan.Scan{Folder: "/music/Justice", Time: time.Now().Add(time.Minute).Unix()}
+	if err := target.Scan(later); !errors.Is(err, autoscan.ErrTargetBusy) {
+		t.Fatalf("expected ErrTargetBusy, got %v", err)
+	}
+
+	s.scanning = false
+	if err := target.Scan(later); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.scans != 2 {
+		t.Errorf("expected 2 scans, got %d", s.scans)
+	}
+}
+
+func TestNewInvalidCredentials(t *testing.T) {
+	srv := newServer(t, &server{})
+	defer srv.Close()
+
+	_, err := New(Config{URL: srv.URL, Username: "admin", Password: "wrong"})
+	if !errors.Is(err, autoscan.ErrFatal) {
+		t.Errorf("expected ErrFatal, got %v", err)
+	}
+}
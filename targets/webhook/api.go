@@ -0,0 +1,93 @@
+// Package webhook provides an autoscan target which sends a templated HTTP request per scan.
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/httpclient"
+)
+
+// defaultUnavailable holds the status codes which mark the target as unavailable,
+// matching the status codes the other targets consider unavailable.
+var defaultUnavailable = []int{
+	http.StatusNotFound,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+type apiClient struct {
+	client      *http.Client
+	log         zerolog.Logger
+	headers     map[string]string
+	unavailable []int
+}
+
+func newAPIClient(headers map[string]string, unavailable []int, log zerolog.Logger) apiClient {
+	if len(unavailable) == 0 {
+		unavailable = defaultUnavailable
+	}
+
+	return apiClient{
+		client:      httpclient.New(),
+		log:         log,
+		headers:     headers,
+		unavailable: unavailable,
+	}
+}
+
+func (c apiClient) do(req *http.Request) (*http.Response, error) {
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	res, err := c.client.Do(req) //nolint:gosec // URL is user-configured in app config, SSRF is intentional
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, autoscan.ErrTargetUnavailable)
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		res.Body = autoscan.LimitReadCloser(res.Body)
+		return res, nil
+	}
+
+	c.log.Trace().
+		Stringer("request_url", res.Request.URL).
+		Int("response_status", res.StatusCode).
+		Msg("Request failed")
+
+	// statusCode not in the 2xx range, close response
+	_ = res.Body.Close()
+
+	if slices.Contains(c.unavailable, res.StatusCode) {
+		return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrTargetUnavailable)
+	}
+
+	return nil, fmt.Errorf("%s: %w", res.Status, autoscan.ErrFatal)
+}
+
+// Send sends the request with the configured headers.
+func (c apiClient) Send(method, reqURL string, body io.Reader) error {
+	// create request
+	req, err := http.NewRequestWithContext(context.Background(), method, reqURL, body)
+	if err != nil {
+		return fmt.Errorf("failed creating webhook request: %w: %w", err, autoscan.ErrFatal)
+	}
+
+	// send request
+	res, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+	return nil
+}
@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// Config holds configuration for the webhook target.
+type Config struct {
+	Name        string             `yaml:"name"`
+	URL         string             `yaml:"url"`
+	Method      string             `yaml:"method"`
+	Headers     map[string]string  `yaml:"headers"`
+	Body        string             `yaml:"body"`
+	Unavailable []int              `yaml:"unavailable-status"`
+	Rewrite     []autoscan.Rewrite `yaml:"rewrite"`
+	Verbosity   string             `yaml:"verbosity"`
+
+	Limits autoscan.TargetLimits `yaml:",inline"`
+}
+
+type target struct {
+	name   string
+	url    string
+	method string
+	body   *template.Template
+
+	log     zerolog.Logger
+	rewrite autoscan.Rewriter
+	api     apiClient
+}
+
+// templateFuncs are the functions available to the body template.
+var templateFuncs = template.FuncMap{
+	// json encodes the value as JSON, for example to quote and escape a path.
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// New creates a webhook target from the given Config.
+func New(cfg Config) (autoscan.Target, error) {
+	logger := autoscan.GetLogger(cfg.Verbosity).With().
+		Str("target", "webhook").
+		Str("url", cfg.URL).
+		Logger()
+
+	rewriter, err := autoscan.NewRewriter(cfg.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("create rewriter: %w", err)
+	}
+
+	method := http.MethodPost
+	if cfg.Method != "" {
+		method = strings.ToUpper(cfg.Method)
+	}
+
+	var body *template.Template
+	if cfg.Body != "" {
+		body, err = template.New("body").Funcs(templateFuncs).Parse(cfg.Body)
+		if err != nil {
+			return nil, fmt.Errorf("parse body template: %w", err)
+		}
+
+		// catch unknown fields on startup rather than on every scan
+		if err := body.Execute(io.Discard, autoscan.Scan{}); err != nil {
+			return nil, fmt.Errorf("render body template: %w", err)
+		}
+	}
+
+	for _, code := range cfg.Unavailable {
+		if code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid unavailable status code: %d", code)
+		}
+	}
+
+	return &target{
+		name:   cfg.Name,
+		url:    cfg.URL,
+		method: method,
+		body:   body,
+
+		log:     logger,
+		rewrite: rewriter,
+		api:     newAPIClient(cfg.Headers, cfg.Unavailable, logger),
+	}, nil
+}
+
+func (t target) ID() string {
+	if t.name != "" {
+		return "webhook:" + t.name
+	}
+
+	return "webhook:" + t.url
+}
+
+// Available always reports the webhook as available, as there is no generic way
+// to check its availability. The next scan checks whether it is back online.
+func (t target) Available() error {
+	return nil
+}
+
+func (t target) Scan(scan autoscan.Scan) error {
+	scan.Folder = t.rewrite(scan.Folder)
+
+	logger := t.log.With().
+		Str("path", scan.Folder).
+		Str("method", t.method).
+		Logger()
+
+	var body io.Reader = http.NoBody
+	if t.body != nil {
+		buf := new(bytes.Buffer)
+		if err := t.body.Execute(buf, scan); err != nil {
+			return fmt.Errorf("render body template: %w: %w", err, autoscan.ErrFatal)
+		}
+
+		body = buf
+	}
+
+	// send scan request
+	logger.Debug().Msg("Scan Sending")
+
+	if err := t.api.Send(t.method, t.url, body); err != nil {
+		return err
+	}
+
+	logger.Info().Msg("Scan Sent")
+	return nil
+}
@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudbox/autoscan"
+)
+
+type request struct {
+	Method      string
+	ContentType string
+	Body        string
+}
+
+func TestScan(t *testing.T) {
+	type Test struct {
+		Name        string
+		Config      Config
+		Status      int
+		Expected    request
+		ExpectedErr error
+	}
+
+	testCases := []Test{
+		{
+			Name: "Templated body",
+			Config: Config{
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    `{"path": {{ json .Folder }}, "file": {{ json .RelativePath }}, "priority": {{ .Priority }}, "time": {{ .Time }}}`,
+			},
+			Status: http.StatusOK,
+			Expected: request{
+				Method:      http.MethodPost,
+				ContentType: "application/json",
+				Body:        `{"path": "/data/Movies/Tenet \"2020\"", "file": "Tenet.mkv", "priority": 5, "time": 1600000000}`,
+			},
+		},
+		{
+			Name: "Without body",
+			Config: Config{
+				Method: "put",
+			},
+			Status: http.StatusNoContent,
+			Expected: request{
+				Method: http.MethodPut,
+			},
+		},
+		{
+			Name:        "Default unavailable status",
+			Config:      Config{},
+			Status:      http.StatusServiceUnavailable,
+			Expected:    request{Method: http.MethodPost},
+			ExpectedErr: autoscan.ErrTargetUnavailable,
+		},
+		{
+			Name:        "Fatal status",
+			Config:      Config{},
+			Status:      http.StatusBadRequest,
+			Expected:    request{Method: http.MethodPost},
+			ExpectedErr: autoscan.ErrFatal,
+		},
+		{
+			Name: "Custom unavailable status",
+			Config: Config{
+				Unavailable: []int{http.StatusTooManyRequests},
+			},
+			Status:      http.StatusTooManyRequests,
+			Expected:    request{Method: http.MethodPost},
+			ExpectedErr: autoscan.ErrTargetUnavailable,
+		},
+		{
+			Name: "Status not in custom unavailable status",
+			Config: Config{
+				Unavailable: []int{http.StatusTooManyRequests},
+			},
+			Status:      http.StatusServiceUnavailable,
+			Expected:    request{Method: http.MethodPost},
+			ExpectedErr: autoscan.ErrFatal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var got request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				got = request{
+					Method:      r.Method,
+					ContentType: r.Header.Get("Content-Type"),
+					Body:        string(body),
+				}
+
+				w.WriteHeader(tc.Status)
+			}))
+			defer server.Close()
+
+			cfg := tc.Config
+			cfg.URL = server.URL
+			cfg.Rewrite = []autoscan.Rewrite{{
+				From: "^/mnt/unionfs/Media/",
+				To:   "/data/",
+			}}
+
+			target, err := New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = target.Scan(autoscan.Scan{
+				Folder:       `/mnt/unionfs/Media/Movies/Tenet "2020"`,
+				RelativePath: "Tenet.mkv",
+				Priority:     5,
+				Time:         1600000000,
+			})
+			if !errors.Is(err, tc.ExpectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.ExpectedErr, err)
+			}
+
+			if got != tc.Expected {
+				t.Errorf("expected request %+v, got %+v", tc.Expected, got)
+			}
+		})
+	}
+}
+
+func TestNewInvalidTemplate(t *testing.T) {
+	testCases := map[string]string{
+		"Syntax error":  `{"path": {{ .Folder }`,
+		"Unknown field": `{"path": {{ .Path }}}`,
+	}
+
+	for name, body := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := New(Config{URL: "http://localhost", Body: body}); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestID(t *testing.T) {
+	testCases := map[string]Config{
+		"webhook:http://localhost/scan": {URL: "http://localhost/scan"},
+		"webhook:movies":                {Name: "movies", URL: "http://localhost/scan"},
+	}
+
+	for expected, cfg := range testCases {
+		t.Run(expected, func(t *testing.T) {
+			target, err := New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if target.ID() != expected {
+				t.Errorf("expected ID %s, got %s", expected, target.ID())
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package bazarr provides an autoscan trigger for Bazarr subtitle downloads.
+package bazarr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/hlog"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// Config holds configuration for the Bazarr trigger.
+type Config struct {
+	Name      string             `yaml:"name"`
+	Priority  int                `yaml:"priority"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Verbosity string             `yaml:"verbosity"`
+}
+
+// New creates an autoscan-compatible HTTP Trigger for Bazarr's custom post-processing.
+func New(c Config) (autoscan.HTTPTrigger, error) {
+	rewriter, err := autoscan.NewRewriter(c.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("create rewriter: %w", err)
+	}
+
+	trigger := func(callback autoscan.ProcessorFunc) http.Handler {
+		return handler{
+			callback: callback,
+			priority: c.Priority,
+			rewrite:  rewriter,
+		}
+	}
+
+	return trigger, nil
+}
+
+type handler struct {
+	priority int
+	rewrite  autoscan.Rewriter
+	callback autoscan.ProcessorFunc
+}
+
+// bazarrEvent is sent by the post-processing command of Bazarr,
+// which fills in the variables of the downloaded subtitle.
+type bazarrEvent struct {
+	// Directory is the folder of the episode or movie ({{directory}}).
+	Directory string `json:"directory"`
+	// Media is the episode or movie file ({{episode}}).
+	Media string `json:"episode"`
+	// Subtitles is the downloaded subtitle file ({{subtitles}}).
+	Subtitles string `json:"subtitles"`
+	Language  string `json:"language"`
+}
+
+func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
+	rlog := hlog.FromRequest(r)
+
+	event := new(bazarrEvent)
+	if err := json.NewDecoder(r.Body).Decode(event); err != nil {
+		rlog.Error().Err(err).Msg("Request Decode Failed")
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rlog.Trace().Interface("event", event).Msg("Webhook Payload")
+
+	folderPath, filePath, err := pathsForSubtitles(event)
+	if err != nil {
+		rlog.Error().Err(err).Msg("Required Fields Missing")
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	scan := autoscan.Scan{
+		Folder:       h.rewrite(folderPath),
+		RelativePath: filePath,
+		Priority:     h.priority,
+		Time:         now().Unix(),
+	}
+
+	if err = h.callback(scan); err != nil {
+		rlog.Error().Err(err).Msg("Scan Enqueue Failed")
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rlog.Info().
+		Str("path", scan.Folder).
+		Str("subtitles", scan.RelativePath).
+		Str("language", event.Language).
+		Msg("Scan Enqueued")
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// pathsForSubtitles returns the folder of the episode or movie, and the path
+// of the subtitle file relative to that folder.
+// Subtitles outside of the media folder are scanned within their own folder.
+func pathsForSubtitles(event *bazarrEvent) (string, string, error) {
+	if !path.IsAbs(event.Subtitles) {
+		return "", "", errors.New("required fields missing")
+	}
+
+	subtitles := path.Clean(event.Subtitles)
+
+	folder := event.Directory
+	if folder == "" && event.Media != "" {
+		folder = path.Dir(event.Media)
+	}
+
+	if folder != "" {
+		if rel, ok := strings.CutPrefix(subtitles, path.Clean(folder)+"/"); ok {
+			return path.Clean(folder), rel, nil
+		}
+	}
+
+	return path.Dir(subtitles), path.Base(subtitles), nil
+}
+
+var now = time.Now
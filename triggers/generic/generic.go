@@ -0,0 +1,263 @@
+// Package generic provides an autoscan trigger for webhooks with arbitrary JSON payloads.
+package generic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/hlog"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// Config holds configuration for a generic webhook trigger.
+//
+// The Event, Folder and File fields hold GJSON-style paths which select
+// the event type, the folders and the files from the JSON payload.
+type Config struct {
+	Name      string             `yaml:"name"`
+	Priority  int                `yaml:"priority"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Include   []string           `yaml:"include"`
+	Exclude   []string           `yaml:"exclude"`
+	Verbosity string             `yaml:"verbosity"`
+
+	Event  string        `yaml:"event"`
+	Folder string        `yaml:"folder"`
+	File   string        `yaml:"file"`
+	Events []EventConfig `yaml:"events"`
+}
+
+// EventConfig holds the configuration of a single event type.
+// When any events are configured, only these event types are scanned.
+type EventConfig struct {
+	Type string `yaml:"type"`
+
+	// Folder and File override the trigger's paths for this event type.
+	Folder string `yaml:"folder"`
+	File   string `yaml:"file"`
+
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// New creates an autoscan-compatible HTTP Trigger for generic JSON webhooks.
+func New(c Config) (autoscan.HTTPTrigger, error) {
+	rewriter, err := autoscan.NewRewriter(c.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("create rewriter: %w", err)
+	}
+
+	event, err := parseQuery(c.Event)
+	if err != nil {
+		return nil, fmt.Errorf("event: %w", err)
+	}
+
+	var defaults mapping
+	if c.Folder != "" || c.File != "" {
+		defaults, err = newMapping(EventConfig{Folder: c.Folder, File: c.File}, c.Include, c.Exclude)
+		if err != nil {
+			return nil, err
+		}
+	} else if len(c.Events) == 0 {
+		return nil, errors.New("folder or file path missing")
+	}
+
+	events := make(map[string]mapping, len(c.Events))
+	for _, e := range c.Events {
+		if e.Type == "" {
+			return nil, errors.New("event type missing")
+		}
+
+		// Unset paths fall back to the paths of the trigger.
+		if e.Folder == "" && e.File == "" {
+			e.Folder, e.File = c.Folder, c.File
+		}
+
+		m, err := newMapping(e, append(e.Include, c.Include...), append(e.Exclude, c.Exclude...))
+		if err != nil {
+			return nil, fmt.Errorf("event %s: %w", e.Type, err)
+		}
+
+		events[strings.ToLower(e.Type)] = m
+	}
+
+	trigger := func(callback autoscan.ProcessorFunc) http.Handler {
+		return handler{
+			callback: callback,
+			priority: c.Priority,
+			rewrite:  rewriter,
+			event:    event,
+			defaults: defaults,
+			events:   events,
+		}
+	}
+
+	return trigger, nil
+}
+
+// mapping selects the folders and files of an event from the payload.
+type mapping struct {
+	folder  query
+	file    query
+	allowed autoscan.Filterer
+}
+
+func newMapping(c EventConfig, includes, excludes []string) (mapping, error) {
+	folder, err := parseQuery(c.Folder)
+	if err != nil {
+		return mapping{}, fmt.Errorf("folder: %w", err)
+	}
+
+	file, err := parseQuery(c.File)
+	if err != nil {
+		return mapping{}, fmt.Errorf("file: %w", err)
+	}
+
+	if folder == nil && file == nil {
+		return mapping{}, errors.New("folder or file path missing")
+	}
+
+	filterer, err := autoscan.NewFilterer(includes, excludes)
+	if err != nil {
+		return mapping{}, fmt.Errorf("create filterer: %w", err)
+	}
+
+	return mapping{folder: folder, file: file, allowed: filterer}, nil
+}
+
+// paths returns the folder→files mapping selected from the payload.
+// Absolute files are scanned within their own folder. Relative files are
+// joined with the selected folder, or paired with the folder at the same index.
+// Without files, the folders are scanned as a whole.
+func (m mapping) paths(payload any) (map[string][]string, error) {
+	folders := m.folder.strings(payload)
+	files := m.file.strings(payload)
+
+	paths := make(map[string][]string)
+	add := func(full string) {
+		folder, file := path.Dir(full), path.Base(full)
+		if !slices.Contains(paths[folder], file) {
+			paths[folder] = append(paths[folder], file)
+		}
+	}
+
+	for i, file := range files {
+		switch {
+		case path.IsAbs(file):
+			add(file)
+		case len(folders) == 1:
+			add(path.Join(folders[0], file))
+		case len(folders) == len(files):
+			add(path.Join(folders[i], file))
+		default:
+			return nil, fmt.Errorf("no folder for relative file %q", file)
+		}
+	}
+
+	if len(files) == 0 {
+		for _, folder := range folders {
+			paths[path.Clean(folder)] = nil
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, errors.New("required fields missing")
+	}
+
+	return paths, nil
+}
+
+type handler struct {
+	priority int
+	rewrite  autoscan.Rewriter
+	callback autoscan.ProcessorFunc
+
+	event    query
+	defaults mapping
+	events   map[string]mapping // by lowercase event type
+}
+
+func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
+	rlog := hlog.FromRequest(r)
+
+	var payload any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		rlog.Error().Err(err).Msg("Request Decode Failed")
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rlog.Trace().Interface("event", payload).Msg("Webhook Payload")
+
+	eventType := h.event.first(payload)
+
+	m := h.defaults
+	if len(h.events) > 0 {
+		var ok bool
+		if m, ok = h.events[strings.ToLower(eventType)]; !ok {
+			rlog.Debug().Str("event", eventType).Msg("Event Ignored")
+			writer.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	paths, err := m.paths(payload)
+	if err != nil {
+		rlog.Error().Err(err).Msg("Required Fields Missing")
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var scans []autoscan.Scan
+
+	for folderPath, files := range paths {
+		if len(files) == 0 {
+			// scan the folder as a whole
+			files = []string{""}
+		}
+
+		folderPath = h.rewrite(folderPath)
+		for _, filePath := range files {
+			if !m.allowed(path.Join(folderPath, filePath)) {
+				continue
+			}
+
+			scans = append(scans, autoscan.Scan{
+				Folder:       folderPath,
+				RelativePath: filePath,
+				Priority:     h.priority,
+				Time:         now().Unix(),
+			})
+		}
+	}
+
+	if len(scans) == 0 {
+		rlog.Debug().Str("event", eventType).Msg("Paths Filtered")
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err = h.callback(scans...); err != nil {
+		rlog.Error().Err(err).Msg("Scan Enqueue Failed")
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	for _, scan := range scans {
+		rlog.Info().
+			Str("path", scan.Folder).
+			Str("event", eventType).
+			Msg("Scan Enqueued")
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+var now = time.Now
@@ -0,0 +1,290 @@
+package generic
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+)
+
+func scansEqual(expected, actual []autoscan.Scan) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	// Sort both slices for comparison
+	sortScans := func(scans []autoscan.Scan) []autoscan.Scan {
+		sorted := make([]autoscan.Scan, len(scans))
+		copy(sorted, scans)
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].Folder != sorted[j].Folder {
+				return sorted[i].Folder < sorted[j].Folder
+			}
+			return sorted[i].RelativePath < sorted[j].RelativePath
+		})
+		return sorted
+	}
+
+	return reflect.DeepEqual(sortScans(expected), sortScans(actual))
+}
+
+func TestHandler(t *testing.T) {
+	type Given struct {
+		Config  Config
+		Fixture string
+	}
+
+	type Expected struct {
+		Scans      []autoscan.Scan
+		StatusCode int
+	}
+
+	type Test struct {
+		Name     string
+		Given    Given
+		Expected Expected
+	}
+
+	fileConfig := Config{
+		Name:     "fileflows",
+		Priority: 5,
+		Event:    "event",
+		File:     "file.path",
+		Exclude:  []string{"/Sample/"},
+		Rewrite: []autoscan.Rewrite{{
+			From: "^/Movies/",
+			To:   "/mnt/unionfs/Media/Movies/",
+		}},
+	}
+
+	eventsConfig := Config{
+		Name:     "sonarr-generic",
+		Priority: 3,
+		Event:    "eventType",
+		Folder:   "series.path",
+		Rewrite: []autoscan.Rewrite{{
+			From: "^/TV/",
+			To:   "/mnt/unionfs/Media/TV/",
+		}},
+		Events: []EventConfig{
+			{Type: "download", File: "episodeFiles.#.relativePath", Folder: "series.path"},
+			{Type: "SeriesDelete"},
+		},
+	}
+
+	currentTime := time.Now()
+	now = func() time.Time {
+		return currentTime
+	}
+
+	testCases := []Test{
+		{
+			"Scans the folder of an absolute file",
+			Given{
+				Config:  fileConfig,
+				Fixture: "testdata/fileflows.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Movies/Tenet (2020)",
+						RelativePath: "Tenet.2020.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Joins relative files with the folder",
+			Given{
+				Config:  eventsConfig,
+				Fixture: "testdata/episodes.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Season 1",
+						RelativePath: "Westworld.S01E01.mkv",
+						Priority:     3,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Season 1",
+						RelativePath: "Westworld.S01E02.mkv",
+						Priority:     3,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Season 2",
+						RelativePath: "Westworld.S02E01.mkv",
+						Priority:     3,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Scans the folder of an event without files",
+			Given{
+				Config:  eventsConfig,
+				Fixture: "testdata/series_delete.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:   "/mnt/unionfs/Media/TV/Westworld",
+						Priority: 3,
+						Time:     currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Ignores events which are not configured",
+			Given{
+				Config:  eventsConfig,
+				Fixture: "testdata/grab.json",
+			},
+			Expected{
+				StatusCode: 200,
+			},
+		},
+		{
+			"Ignores excluded paths",
+			Given{
+				Config:  fileConfig,
+				Fixture: "testdata/sample.json",
+			},
+			Expected{
+				StatusCode: 200,
+			},
+		},
+		{
+			"Returns bad request on missing paths",
+			Given{
+				Config:  fileConfig,
+				Fixture: "testdata/missing.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
+		{
+			"Returns bad request on invalid JSON",
+			Given{
+				Config:  fileConfig,
+				Fixture: "testdata/invalid.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			callback := func(scans ...autoscan.Scan) error {
+				if !scansEqual(tc.Expected.Scans, scans) {
+					t.Log(scans)
+					t.Log(tc.Expected.Scans)
+					t.Error("Scans do not equal")
+					return errors.New("Scans do not equal")
+				}
+
+				return nil
+			}
+
+			trigger, err := New(tc.Given.Config)
+			if err != nil {
+				t.Fatalf("Could not create Generic Trigger: %v", err)
+			}
+
+			server := httptest.NewServer(trigger(callback))
+			defer server.Close()
+
+			request, err := os.Open(tc.Given.Fixture)
+			if err != nil {
+				t.Fatalf("Could not open the fixture: %s", tc.Given.Fixture)
+			}
+
+			res, err := http.Post(server.URL, "application/json", request)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			defer func() { _ = res.Body.Close() }()
+			if res.StatusCode != tc.Expected.StatusCode {
+				t.Errorf("Status codes do not match: %d vs %d", res.StatusCode, tc.Expected.StatusCode)
+			}
+		})
+	}
+}
+
+func TestNewConfigErrors(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Config Config
+	}{
+		{"No paths", Config{Name: "generic"}},
+		{"Empty key", Config{Name: "generic", Folder: "movie..path"}},
+		{"Event without type", Config{Name: "generic", Folder: "path", Events: []EventConfig{{}}}},
+		{"Invalid filter", Config{Name: "generic", Folder: "path", Include: []string{"("}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if _, err := New(tc.Config); err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	var payload any
+	err := json.Unmarshal([]byte(`{
+		"movie": {"folderPath": "/Movies/Tenet (2020)"},
+		"files": [{"path": "/a.mkv"}, {"path": "/b.mkv"}, {"size": 1}],
+		"tags": ["4k", "", "hdr"],
+		"dotted.key": "value"
+	}`), &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Path     string
+		Expected []string
+	}{
+		{"movie.folderPath", []string{"/Movies/Tenet (2020)"}},
+		{"files.#.path", []string{"/a.mkv", "/b.mkv"}},
+		{"files.1.path", []string{"/b.mkv"}},
+		{"files.3.path", nil},
+		{"tags", []string{"4k", "hdr"}},
+		{`dotted\.key`, []string{"value"}},
+		{"movie.missing", nil},
+		{"", nil},
+	}
+
+	for _, tc := range testCases {
+		q, err := parseQuery(tc.Path)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.Path, err)
+		}
+
+		if got := q.strings(payload); !reflect.DeepEqual(got, tc.Expected) {
+			t.Errorf("%s: expected %v, got %v", tc.Path, tc.Expected, got)
+		}
+	}
+}
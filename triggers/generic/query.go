@@ -0,0 +1,124 @@
+package generic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A query selects values from a decoded JSON payload using a GJSON-style path.
+//
+// The path is a dot-separated list of object keys and array indexes,
+// for example `movie.folderPath` or `episodeFiles.0.path`.
+// A `#` selects every element of an array, so `episodeFiles.#.path` returns
+// the path of every episode file. Dots within keys are escaped with a backslash.
+type query []string
+
+var errEmptyKey = errors.New("empty key")
+
+// parseQuery splits the path into its keys.
+// An empty path returns a nil query which never selects any values.
+func parseQuery(path string) (query, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	var (
+		keys []string
+		key  strings.Builder
+	)
+
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			key.WriteByte(path[i])
+		case c == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(c)
+		}
+	}
+
+	keys = append(keys, key.String())
+
+	for _, k := range keys {
+		if k == "" {
+			return nil, fmt.Errorf("parse %q: %w", path, errEmptyKey)
+		}
+	}
+
+	return keys, nil
+}
+
+// strings returns all non-empty string values selected by the query.
+func (q query) strings(value any) []string {
+	if q == nil {
+		return nil
+	}
+
+	return collect(value, q)
+}
+
+// first returns the first string value selected by the query.
+func (q query) first(value any) string {
+	if values := q.strings(value); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func collect(value any, keys []string) []string {
+	if len(keys) == 0 {
+		switch v := value.(type) {
+		case string:
+			if v != "" {
+				return []string{v}
+			}
+		case []any:
+			// a path pointing to an array of strings selects every string
+			var values []string
+			for _, elem := range v {
+				if s, ok := elem.(string); ok && s != "" {
+					values = append(values, s)
+				}
+			}
+			return values
+		}
+
+		return nil
+	}
+
+	key, rest := keys[0], keys[1:]
+
+	switch v := value.(type) {
+	case map[string]any:
+		child, ok := v[key]
+		if !ok {
+			return nil
+		}
+
+		return collect(child, rest)
+
+	case []any:
+		if key == "#" {
+			var values []string
+			for _, elem := range v {
+				values = append(values, collect(elem, rest)...)
+			}
+			return values
+		}
+
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil
+		}
+
+		return collect(v[i], rest)
+	}
+
+	return nil
+}
@@ -2,35 +2,47 @@
 package inotify
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/rs/zerolog"
 
 	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/snapshot"
 )
 
+// defaultDelay is the default settle window of a folder.
+const defaultDelay = 10 * time.Second
+
 // Config holds configuration for the inotify trigger.
 type Config struct {
-	Priority  int                `yaml:"priority"`
-	Verbosity string             `yaml:"verbosity"`
-	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
-	Include   []string           `yaml:"include"`
-	Exclude   []string           `yaml:"exclude"`
-	Paths     []PathConfig       `yaml:"paths"`
+	Priority   int                `yaml:"priority"`
+	Verbosity  string             `yaml:"verbosity"`
+	Delay      time.Duration      `yaml:"delay"`
+	StableSize bool               `yaml:"stable-size"`
+	Rewrite    []autoscan.Rewrite `yaml:"rewrite"`
+	Include    []string           `yaml:"include"`
+	Exclude    []string           `yaml:"exclude"`
+	Paths      []PathConfig       `yaml:"paths"`
 }
 
 // PathConfig holds per-path overrides for the inotify trigger.
 type PathConfig struct {
-	Path    string             `yaml:"path"`
-	Rewrite []autoscan.Rewrite `yaml:"rewrite"`
-	Include []string           `yaml:"include"`
-	Exclude []string           `yaml:"exclude"`
+	Path       string             `yaml:"path"`
+	Delay      time.Duration      `yaml:"delay"`
+	StableSize *bool              `yaml:"stable-size"`
+	Rewrite    []autoscan.Rewrite `yaml:"rewrite"`
+	Include    []string           `yaml:"include"`
+	Exclude    []string           `yaml:"exclude"`
 }
 
 type daemon struct {
@@ -39,12 +51,18 @@ type daemon struct {
 	watcher  *fsnotify.Watcher
 	queue    *queue
 	log      zerolog.Logger
+
+	mu     sync.Mutex
+	roots  map[string]*rootState                 // by path
+	polled map[string]map[string]snapshot.Folder // snapshots of the subtrees which could not be watched
 }
 
 type path struct {
-	Path     string
-	Rewriter autoscan.Rewriter
-	Allowed  autoscan.Filterer
+	Path       string
+	Rewriter   autoscan.Rewriter
+	Allowed    autoscan.Filterer
+	Delay      time.Duration
+	StableSize bool
 }
 
 // New creates an inotify-based autoscan trigger that watches the configured paths.
@@ -67,19 +85,30 @@ func New(cfg Config) (autoscan.Trigger, error) {
 			return nil, fmt.Errorf("create path filterer: %w", err)
 		}
 
+		delay := cmp.Or(pathConfig.Delay, cfg.Delay, defaultDelay)
+
+		stableSize := cfg.StableSize
+		if pathConfig.StableSize != nil {
+			stableSize = *pathConfig.StableSize
+		}
+
 		paths = append(paths, path{
-			Path:     pathConfig.Path,
-			Rewriter: rewriter,
-			Allowed:  filterer,
+			Path:       filepath.Clean(pathConfig.Path),
+			Rewriter:   rewriter,
+			Allowed:    filterer,
+			Delay:      delay,
+			StableSize: stableSize,
 		})
 	}
 
 	trigger := func(callback autoscan.ProcessorFunc) {
-		d := daemon{
+		d := &daemon{
 			log:      logger,
 			callback: callback,
 			paths:    paths,
 			queue:    newQueue(callback, logger, cfg.Priority),
+			roots:    make(map[string]*rootState),
+			polled:   make(map[string]map[string]snapshot.Folder),
 		}
 
 		// start job(s)
@@ -102,23 +131,24 @@ func (d *daemon) startMonitoring() error {
 	}
 	d.watcher = watcher
 
-	// setup watcher
+	// setup watcher, missing paths are watched once they appear
 	for _, p := range d.paths {
-		if err := filepath.Walk(p.Path, d.walkFunc); err != nil {
-			_ = d.watcher.Close()
-			return fmt.Errorf("walk path %s: %w", p.Path, err)
-		}
+		d.checkRoot(p)
 	}
 
-	// start worker
+	// start workers
 	go d.worker()
+	go d.monitor()
 
 	return nil
 }
 
 func (d *daemon) walkFunc(path string, fi os.FileInfo, err error) error {
 	// handle error
-	if err != nil {
+	if errors.Is(err, fs.ErrNotExist) {
+		// removed while walking
+		return nil
+	} else if err != nil {
 		return fmt.Errorf("walk func: %v: %w", path, err)
 	}
 
@@ -128,6 +158,12 @@ func (d *daemon) walkFunc(path string, fi os.FileInfo, err error) error {
 	}
 
 	if err := d.watcher.Add(path); err != nil {
+		if errors.Is(err, syscall.ENOSPC) {
+			// fs.inotify.max_user_watches reached
+			d.pollSubtree(path, err)
+			return filepath.SkipDir
+		}
+
 		return fmt.Errorf("watch directory: %v: %w", path, err)
 	}
 
@@ -161,9 +197,11 @@ func (d *daemon) worker() {
 				Interface("event", event).
 				Msg("FS Event")
 
+			var isDir bool
+
 			switch {
 			case event.Op&fsnotify.Create == fsnotify.Create:
-				// create
+				// created / moved in
 				fi, err := os.Stat(event.Name)
 				if err != nil {
 					d.log.Error().
@@ -173,7 +211,8 @@ func (d *daemon) worker() {
 					continue
 				}
 
-				// watch new directories
+				// watch new directories, and scan them as their
+				// contents do not cause events when moved in
 				if fi.IsDir() {
 					if err := filepath.Walk(event.Name, d.walkFunc); err != nil {
 						d.log.Error().
@@ -182,9 +221,13 @@ func (d *daemon) worker() {
 							Msg("Watch Failed")
 					}
 
-					continue
+					isDir = true
 				}
 
+			case event.Op&fsnotify.Write == fsnotify.Write:
+				// written, restarts the settle window of the folder.
+				// fsnotify does not expose close-write events, so a file which
+				// is still open can settle when its writer pauses for too long.
 			case event.Op&fsnotify.Rename == fsnotify.Rename, event.Op&fsnotify.Remove == fsnotify.Remove:
 				// renamed / removed
 			default:
@@ -192,32 +235,7 @@ func (d *daemon) worker() {
 				continue
 			}
 
-			// get path object
-			pathObj, err := d.getPathObject(event.Name)
-			if err != nil {
-				d.log.Error().
-					Err(err).
-					Str("path", event.Name).
-					Msg("Path Match Failed")
-				continue
-			}
-
-			// rewrite
-			rewritten := pathObj.Rewriter(event.Name)
-
-			// filter
-			if !pathObj.Allowed(rewritten) {
-				continue
-			}
-
-			// get directory where path has an extension
-			if filepath.Ext(rewritten) != "" {
-				// there was most likely a file extension, use the directory
-				rewritten = filepath.Dir(rewritten)
-			}
-
-			// move to queue
-			d.queue.inputs <- rewritten
+			d.enqueue(event.Name, isDir)
 
 		case err := <-d.watcher.Errors:
 			d.log.Error().
@@ -227,101 +245,39 @@ func (d *daemon) worker() {
 	}
 }
 
-type queue struct {
-	callback autoscan.ProcessorFunc
-	log      zerolog.Logger
-	priority int
-	inputs   chan string
-	scans    map[string]time.Time
-	lock     *sync.Mutex
-}
-
-func newQueue(cb autoscan.ProcessorFunc, log zerolog.Logger, priority int) *queue {
-	scanQueue := &queue{
-		callback: cb,
-		log:      log,
-		priority: priority,
-		inputs:   make(chan string),
-		scans:    make(map[string]time.Time),
-		lock:     &sync.Mutex{},
-	}
-
-	go scanQueue.worker()
-
-	return scanQueue
-}
-
-func (q *queue) add(path string) {
-	// acquire lock
-	q.lock.Lock()
-	defer q.lock.Unlock()
-
-	// queue scan task
-	q.scans[path] = time.Now().Add(10 * time.Second)
-}
-
-func (q *queue) worker() {
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case path, ok := <-q.inputs:
-			if !ok {
-				return
-			}
-			q.add(path)
-		case <-ticker.C:
-			q.process()
-		}
+// enqueue queues a scan of the changed path, or of its folder when the path is a file.
+func (d *daemon) enqueue(name string, isDir bool) {
+	// get path object
+	pathObj, err := d.getPathObject(name)
+	if err != nil {
+		d.log.Error().
+			Err(err).
+			Str("path", name).
+			Msg("Path Match Failed")
+		return
 	}
-}
 
-func (q *queue) process() {
-	q.lock.Lock()
+	// rewrite
+	rewritten := pathObj.Rewriter(name)
 
-	if len(q.scans) == 0 {
-		q.lock.Unlock()
+	// filter
+	if !pathObj.Allowed(rewritten) {
 		return
 	}
 
-	// collect ready scans under lock
-	type readyScan struct {
-		path string
-		scan autoscan.Scan
-	}
-
-	var ready []readyScan
-	now := time.Now()
-	for pathStr, t := range q.scans {
-		if now.Before(t) {
-			continue
-		}
-		ready = append(ready, readyScan{
-			path: pathStr,
-			scan: autoscan.Scan{
-				Folder:   filepath.Clean(pathStr),
-				Priority: q.priority,
-				Time:     now.Unix(),
-			},
-		})
-		delete(q.scans, pathStr)
+	// get directory where path has an extension
+	local := name
+	if !isDir && filepath.Ext(rewritten) != "" {
+		// there was most likely a file extension, use the directory
+		rewritten = filepath.Dir(rewritten)
+		local = filepath.Dir(local)
 	}
 
-	q.lock.Unlock()
-
-	// call callbacks outside lock
-	for _, r := range ready {
-		err := q.callback(r.scan)
-		if err != nil {
-			q.log.Error().
-				Err(err).
-				Str("path", r.path).
-				Msg("Scan Enqueue Failed")
-		} else {
-			q.log.Info().
-				Str("path", r.path).
-				Msg("Scan Enqueued")
-		}
+	// move to queue
+	d.queue.inputs <- queueItem{
+		folder:     rewritten,
+		local:      local,
+		delay:      pathObj.Delay,
+		stableSize: pathObj.StableSize,
 	}
 }
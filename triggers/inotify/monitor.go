@@ -0,0 +1,277 @@
+package inotify
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/cloudbox/autoscan/internal/snapshot"
+)
+
+// monitorInterval is the interval at which lost paths are watched again,
+// and the subtrees which could not be watched are polled for changes.
+const monitorInterval = time.Minute
+
+type rootStatus int
+
+const (
+	statusUnknown rootStatus = iota
+	// statusLost means the path is missing or could not be watched.
+	statusLost
+	// statusWatching means all folders below the path are watched.
+	statusWatching
+	// statusPolling means some folders below the path are polled,
+	// as the inotify watch limit was reached.
+	statusPolling
+)
+
+func (s rootStatus) String() string {
+	switch s {
+	case statusLost:
+		return "lost"
+	case statusWatching:
+		return "watching"
+	case statusPolling:
+		return "polling"
+	default:
+		return "unknown"
+	}
+}
+
+type rootState struct {
+	status rootStatus
+	info   os.FileInfo // the path as it was watched
+}
+
+func (d *daemon) monitor() {
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.pollSubtrees()
+
+		for _, p := range d.paths {
+			d.checkRoot(p)
+		}
+	}
+}
+
+// checkRoot watches the path when it is not watched yet, or when its watches were lost.
+// Watches are lost when the path is removed or unmounted. A path which is mounted
+// again is a different directory, so the path is watched again when it changed.
+func (d *daemon) checkRoot(p path) {
+	fi, err := os.Stat(p.Path)
+	if err != nil {
+		d.setStatus(p.Path, statusLost, nil, err)
+		return
+	}
+
+	d.mu.Lock()
+	state := d.roots[p.Path]
+	d.mu.Unlock()
+
+	watched := state != nil && state.status > statusLost && os.SameFile(state.info, fi) &&
+		(slices.Contains(d.watcher.WatchList(), p.Path) || d.isPolled(p.Path))
+
+	if !watched {
+		d.unwatch(p.Path)
+
+		if err := filepath.Walk(p.Path, d.walkFunc); err != nil {
+			d.setStatus(p.Path, statusLost, nil, err)
+			return
+		}
+	}
+
+	status := statusWatching
+	if d.isPolling(p.Path) {
+		status = statusPolling
+	}
+
+	d.setStatus(p.Path, status, fi, nil)
+}
+
+// setStatus updates the status of the path and logs its changes.
+func (d *daemon) setStatus(root string, status rootStatus, info os.FileInfo, err error) {
+	d.mu.Lock()
+	state, ok := d.roots[root]
+	if !ok {
+		state = &rootState{}
+		d.roots[root] = state
+	}
+
+	prev := state.status
+	state.status = status
+	if info != nil {
+		state.info = info
+	}
+
+	subtrees := 0
+	for dir := range d.polled {
+		if snapshot.Within(dir, root) {
+			subtrees++
+		}
+	}
+	d.mu.Unlock()
+
+	if prev == status {
+		return
+	}
+
+	switch status {
+	case statusLost:
+		d.log.Warn().
+			Err(err).
+			Str("path", root).
+			Stringer("previous", prev).
+			Msg("Watch Lost")
+	case statusPolling:
+		d.log.Warn().
+			Str("path", root).
+			Int("polled", subtrees).
+			Msg("Watch Degraded")
+	default:
+		d.log.Info().
+			Str("path", root).
+			Stringer("previous", prev).
+			Msg("Watch Established")
+	}
+}
+
+// unwatch removes the watches and the polled subtrees below the path.
+func (d *daemon) unwatch(root string) {
+	for _, name := range d.watcher.WatchList() {
+		if snapshot.Within(name, root) {
+			_ = d.watcher.Remove(name)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	maps.DeleteFunc(d.polled, func(dir string, _ map[string]snapshot.Folder) bool {
+		return snapshot.Within(dir, root)
+	})
+}
+
+// pollSubtree polls the folder and its subfolders, as they cannot be watched.
+func (d *daemon) pollSubtree(dir string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.polled[dir]; ok {
+		return
+	}
+
+	// the first poll only creates the snapshot
+	d.polled[dir] = nil
+
+	d.log.Warn().
+		Err(err).
+		Str("path", dir).
+		Msg("Watch Limit Reached")
+}
+
+// isPolled reports whether the folder is the root of a polled subtree.
+func (d *daemon) isPolled(dir string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, ok := d.polled[dir]
+	return ok
+}
+
+// isPolling reports whether any folder below the path is polled.
+func (d *daemon) isPolling(root string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for dir := range d.polled {
+		if snapshot.Within(dir, root) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pollSubtrees enqueues the folders which changed within the polled subtrees
+// since the previous poll. Subtrees are watched again once watches are available.
+func (d *daemon) pollSubtrees() {
+	d.mu.Lock()
+	subtrees := slices.Sorted(maps.Keys(d.polled))
+	d.mu.Unlock()
+
+	for _, dir := range subtrees {
+		current := readSubtree(dir)
+
+		d.mu.Lock()
+		prev, ok := d.polled[dir]
+		if ok {
+			d.polled[dir] = current
+		}
+		d.mu.Unlock()
+
+		if !ok {
+			// unwatched in the meantime
+			continue
+		}
+
+		if prev != nil {
+			changed, _, _ := snapshot.Diff(prev, current)
+			for _, folder := range changed {
+				d.enqueue(folder, true)
+			}
+		}
+
+		err := d.watcher.Add(dir)
+		if errors.Is(err, syscall.ENOSPC) {
+			continue
+		}
+
+		d.mu.Lock()
+		delete(d.polled, dir)
+		d.mu.Unlock()
+
+		if err != nil {
+			// the subtree is gone
+			continue
+		}
+
+		if err := filepath.Walk(dir, d.walkFunc); err != nil {
+			d.log.Error().
+				Err(err).
+				Str("path", dir).
+				Msg("Watch Failed")
+			continue
+		}
+
+		d.log.Info().
+			Str("path", dir).
+			Msg("Watch Restored")
+	}
+}
+
+// readSubtree returns the snapshot of the folder and all its subfolders.
+func readSubtree(dir string) map[string]snapshot.Folder {
+	folders := make(map[string]snapshot.Folder)
+
+	var visit func(dir string)
+	visit = func(dir string) {
+		f, subdirs, err := snapshot.Read(dir)
+		if err != nil {
+			return
+		}
+
+		folders[dir] = f
+		for _, name := range subdirs {
+			visit(filepath.Join(dir, name))
+		}
+	}
+
+	visit(dir)
+	return folders
+}
@@ -0,0 +1,146 @@
+package inotify
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/snapshot"
+)
+
+func getDaemon(t *testing.T, root string) *daemon {
+	t.Helper()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = watcher.Close()
+	})
+
+	// drain the events, as the worker does
+	go func() {
+		for range watcher.Events {
+		}
+	}()
+
+	rewriter, err := autoscan.NewRewriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filterer, err := autoscan.NewFilterer(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &daemon{
+		paths: []path{{
+			Path:     root,
+			Rewriter: rewriter,
+			Allowed:  filterer,
+			Delay:    defaultDelay,
+		}},
+		watcher: watcher,
+		queue:   &queue{inputs: make(chan queueItem, 10)},
+		log:     zerolog.Nop(),
+		roots:   make(map[string]*rootState),
+		polled:  make(map[string]map[string]snapshot.Folder),
+	}
+}
+
+func TestCheckRootRestoresLostPath(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "Media")
+	d := getDaemon(t, root)
+
+	// the path is missing at startup
+	d.checkRoot(d.paths[0])
+	if status := d.roots[root].status; status != statusLost {
+		t.Fatalf("expected status lost, got %s", status)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "Movies"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	d.checkRoot(d.paths[0])
+	if status := d.roots[root].status; status != statusWatching {
+		t.Fatalf("expected status watching, got %s", status)
+	}
+
+	watches := d.watcher.WatchList()
+	if !slices.Contains(watches, root) || !slices.Contains(watches, filepath.Join(root, "Movies")) {
+		t.Fatalf("expected the path to be watched, got %v", watches)
+	}
+
+	// the path is replaced, as with a remounted filesystem
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait for the removed watches to be processed
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.MkdirAll(filepath.Join(root, "TV"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	d.checkRoot(d.paths[0])
+
+	fi, err := os.Stat(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(d.roots[root].info, fi) {
+		t.Error("expected the replaced path to be watched")
+	}
+
+	watches = d.watcher.WatchList()
+	if !slices.Contains(watches, filepath.Join(root, "TV")) || slices.Contains(watches, filepath.Join(root, "Movies")) {
+		t.Errorf("expected the watches of the replaced path, got %v", watches)
+	}
+}
+
+func TestPollSubtrees(t *testing.T) {
+	root := t.TempDir()
+	movies := filepath.Join(root, "Movies")
+	if err := os.MkdirAll(filepath.Join(movies, "Tenet (2020)"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	d := getDaemon(t, root)
+
+	// the subtree could not be watched and was polled before
+	d.polled[movies] = readSubtree(movies)
+
+	if err := os.WriteFile(filepath.Join(movies, "Tenet (2020)", "Tenet.mkv"), []byte("movie"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d.pollSubtrees()
+
+	if len(d.queue.inputs) != 1 {
+		t.Fatalf("expected 1 queued folder, got %d", len(d.queue.inputs))
+	}
+
+	if item := <-d.queue.inputs; item.folder != filepath.Join(movies, "Tenet (2020)") {
+		t.Errorf("expected the changed folder to be queued, got %s", item.folder)
+	}
+
+	// watches are available again
+	if d.isPolling(root) {
+		t.Error("expected the subtree to be watched again")
+	}
+
+	if watches := d.watcher.WatchList(); !slices.Contains(watches, filepath.Join(movies, "Tenet (2020)")) {
+		t.Errorf("expected the subtree to be watched, got %v", watches)
+	}
+}
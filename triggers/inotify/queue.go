@@ -0,0 +1,192 @@
+package inotify
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// queueItem is a folder which received a filesystem event.
+type queueItem struct {
+	folder     string // rewritten folder to scan
+	local      string // folder on the local filesystem
+	delay      time.Duration
+	stableSize bool
+}
+
+// pendingScan is a folder waiting for its settle window to pass.
+type pendingScan struct {
+	queueItem
+	due time.Time
+
+	// sizes holds the file sizes of the folder at the previous check,
+	// nil until the folder was checked once.
+	sizes map[string]int64
+}
+
+type queue struct {
+	callback autoscan.ProcessorFunc
+	log      zerolog.Logger
+	priority int
+	inputs   chan queueItem
+	scans    map[string]*pendingScan
+	lock     *sync.Mutex
+}
+
+func newQueue(cb autoscan.ProcessorFunc, log zerolog.Logger, priority int) *queue {
+	scanQueue := &queue{
+		callback: cb,
+		log:      log,
+		priority: priority,
+		inputs:   make(chan queueItem),
+		scans:    make(map[string]*pendingScan),
+		lock:     &sync.Mutex{},
+	}
+
+	go scanQueue.worker()
+
+	return scanQueue
+}
+
+// add queues a scan of the folder, or restarts the settle window of a queued scan.
+func (q *queue) add(item queueItem) {
+	// acquire lock
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	scan, ok := q.scans[item.folder]
+	if !ok {
+		scan = &pendingScan{queueItem: item}
+		q.scans[item.folder] = scan
+	}
+
+	// queue scan task
+	scan.due = time.Now().Add(item.delay)
+}
+
+func (q *queue) worker() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case item, ok := <-q.inputs:
+			if !ok {
+				return
+			}
+			q.add(item)
+		case <-ticker.C:
+			q.process()
+		}
+	}
+}
+
+func (q *queue) process() {
+	q.lock.Lock()
+
+	if len(q.scans) == 0 {
+		q.lock.Unlock()
+		return
+	}
+
+	// collect ready scans under lock
+	var ready []*pendingScan
+	now := time.Now()
+	for pathStr, scan := range q.scans {
+		if now.Before(scan.due) {
+			continue
+		}
+		ready = append(ready, scan)
+		delete(q.scans, pathStr)
+	}
+
+	q.lock.Unlock()
+
+	// check sizes and call callbacks outside lock
+	for _, r := range ready {
+		if r.stableSize && !q.settled(r) {
+			continue
+		}
+
+		scan := autoscan.Scan{
+			Folder:   filepath.Clean(r.folder),
+			Priority: q.priority,
+			Time:     now.Unix(),
+		}
+
+		err := q.callback(scan)
+		if err != nil {
+			q.log.Error().
+				Err(err).
+				Str("path", r.folder).
+				Msg("Scan Enqueue Failed")
+		} else {
+			q.log.Info().
+				Str("path", r.folder).
+				Msg("Scan Enqueued")
+		}
+	}
+}
+
+// settled reports whether the file sizes of the folder did not change since
+// the previous check. Otherwise, the scan is queued for another settle window.
+func (q *queue) settled(scan *pendingScan) bool {
+	sizes, err := fileSizes(scan.local)
+	if err != nil {
+		// the folder is gone, scan it right away
+		return true
+	}
+
+	if scan.sizes != nil && maps.Equal(scan.sizes, sizes) {
+		return true
+	}
+
+	q.log.Debug().
+		Str("path", scan.folder).
+		Msg("Folder Not Settled")
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if queued, ok := q.scans[scan.folder]; ok {
+		// a new event restarted the settle window in the meantime
+		queued.sizes = sizes
+		return false
+	}
+
+	scan.sizes = sizes
+	scan.due = time.Now().Add(scan.delay)
+	q.scans[scan.folder] = scan
+	return false
+}
+
+// fileSizes returns the sizes of the files directly within the folder.
+func fileSizes(dir string) (map[string]int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int64, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			// removed since the folder was read
+			continue
+		}
+
+		sizes[e.Name()] = info.Size()
+	}
+
+	return sizes, nil
+}
@@ -1,6 +1,7 @@
 package inotify
 
 import (
+	"os"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -25,15 +26,15 @@ func TestQueueProcessesAfterDelay(t *testing.T) {
 	log := zerolog.Nop()
 	q := newQueue(cb, log, 5)
 
-	q.inputs <- "/media/movies/test"
+	q.inputs <- queueItem{folder: "/media/movies/test", delay: 10 * time.Second}
 
 	// Wait for the worker to pick up the input
 	time.Sleep(200 * time.Millisecond)
 
 	// Override the scan time to be in the past so process() picks it up
 	q.lock.Lock()
-	for k := range q.scans {
-		q.scans[k] = time.Now().Add(-1 * time.Second)
+	for _, scan := range q.scans {
+		scan.due = time.Now().Add(-1 * time.Second)
 	}
 	q.lock.Unlock()
 
@@ -69,8 +70,8 @@ func TestQueueWorkerExitsOnChannelClose(t *testing.T) {
 		callback: cb,
 		log:      log,
 		priority: 1,
-		inputs:   make(chan string),
-		scans:    make(map[string]time.Time),
+		inputs:   make(chan queueItem),
+		scans:    make(map[string]*pendingScan),
 		lock:     &sync.Mutex{},
 	}
 
@@ -91,3 +92,83 @@ func TestQueueWorkerExitsOnChannelClose(t *testing.T) {
 		t.Fatal("worker did not exit after channel close")
 	}
 }
+
+func TestQueueRestartsSettleWindow(t *testing.T) {
+	var received []autoscan.Scan
+	q := &queue{
+		callback: func(scans ...autoscan.Scan) error {
+			received = append(received, scans...)
+			return nil
+		},
+		log:   zerolog.Nop(),
+		scans: make(map[string]*pendingScan),
+		lock:  &sync.Mutex{},
+	}
+
+	item := queueItem{folder: "/media/movies/test", delay: 50 * time.Millisecond}
+	q.add(item)
+
+	time.Sleep(30 * time.Millisecond)
+	q.add(item) // written again
+
+	time.Sleep(30 * time.Millisecond)
+	q.process()
+	if len(received) != 0 {
+		t.Fatalf("expected the settle window to restart, got %d scans", len(received))
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	q.process()
+	if len(received) != 1 {
+		t.Fatalf("expected 1 scan, got %d", len(received))
+	}
+}
+
+func TestQueueWaitsForStableSize(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "movie.mkv")
+	if err := os.WriteFile(file, []byte("part"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var received []autoscan.Scan
+	q := &queue{
+		callback: func(scans ...autoscan.Scan) error {
+			received = append(received, scans...)
+			return nil
+		},
+		log:   zerolog.Nop(),
+		scans: make(map[string]*pendingScan),
+		lock:  &sync.Mutex{},
+	}
+
+	q.add(queueItem{folder: "/media/movies/test", local: dir, delay: 10 * time.Millisecond, stableSize: true})
+
+	// expire the settle window of the queued scan and process it
+	expire := func() {
+		time.Sleep(20 * time.Millisecond)
+		q.process()
+	}
+
+	// the first check records the sizes
+	expire()
+	if len(received) != 0 || len(q.scans) != 1 {
+		t.Fatalf("expected the scan to wait for a second check, got %d scans", len(received))
+	}
+
+	// the file is still growing
+	if err := os.WriteFile(file, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	expire()
+	if len(received) != 0 {
+		t.Fatalf("expected the scan to wait for the file, got %d scans", len(received))
+	}
+
+	// the size did not change since the previous check
+	expire()
+	if len(received) != 1 || len(q.scans) != 0 {
+		t.Fatalf("expected 1 scan, got %d", len(received))
+	}
+}
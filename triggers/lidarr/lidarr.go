@@ -3,15 +3,16 @@ package lidarr
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"path"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/hlog"
 
 	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/arr"
 )
 
 // Config holds configuration for the Lidarr trigger.
@@ -50,20 +51,31 @@ type lidarrFile struct {
 	Path string `json:"path"`
 }
 
+type lidarrArtist struct {
+	Path string `json:"path"`
+}
+
+type lidarrRenamedFile struct {
+	// use PreviousPath as the Artist.Path might have changed.
+	PreviousPath string `json:"previousPath"`
+	Path         string `json:"path"`
+}
+
 type lidarrEvent struct {
 	Type    string `json:"eventType"`
 	Upgrade bool   `json:"isUpgrade"`
 
-	Files []lidarrFile `json:"trackFiles"`
+	Files        []lidarrFile        `json:"trackFiles"`
+	File         lidarrFile          `json:"trackFile"`
+	Artist       lidarrArtist        `json:"artist"`
+	RenamedFiles []lidarrRenamedFile `json:"renamedTrackFiles"`
 }
 
 func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
-	var err error
 	logger := hlog.FromRequest(r)
 
 	event := new(lidarrEvent)
-	err = json.NewDecoder(r.Body).Decode(event)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(event); err != nil {
 		logger.Error().Err(err).Msg("Request Decode Failed")
 		writer.WriteHeader(http.StatusBadRequest)
 		return
@@ -77,42 +89,116 @@ func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !strings.EqualFold(event.Type, "Download") || len(event.Files) == 0 {
-		logger.Error().Msg("Required Fields Missing")
+	var (
+		paths map[string][]string
+		err   error
+	)
+
+	switch {
+	case strings.EqualFold(event.Type, "Download"):
+		paths, err = pathsForDownload(event)
+	case strings.EqualFold(event.Type, "TrackFileDelete"):
+		paths, err = pathsForTrackFileDelete(event)
+	case strings.EqualFold(event.Type, "AlbumDelete") || strings.EqualFold(event.Type, "ArtistDelete"):
+		// Lidarr does not send the album folder, so the whole artist is scanned.
+		paths, err = pathsForArtist(event)
+	case strings.EqualFold(event.Type, "Rename"):
+		paths, err = pathsForRename(event)
+	default:
+		// unknown event type — nothing to scan
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Msg("Required Fields Missing")
 		writer.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	unique := make(map[string]bool)
-	scans := make([]autoscan.Scan, 0)
+	var scans []autoscan.Scan
 
-	for _, f := range event.Files {
-		folderPath := path.Dir(h.rewrite(f.Path))
-		if _, ok := unique[folderPath]; ok {
-			continue
+	for folderPath, files := range paths {
+		if len(files) == 0 {
+			// scan the folder as a whole
+			files = []string{""}
 		}
 
-		// add scan
-		unique[folderPath] = true
-		scans = append(scans, autoscan.Scan{
-			Folder:   folderPath,
-			Priority: h.priority,
-			Time:     now().Unix(),
-		})
+		for _, filePath := range files {
+			scans = append(scans, autoscan.Scan{
+				Folder:       h.rewrite(folderPath),
+				RelativePath: filePath,
+				Priority:     h.priority,
+				Time:         now().Unix(),
+			})
+		}
 	}
 
-	err = h.callback(scans...)
-	if err != nil {
+	if err = h.callback(scans...); err != nil {
 		logger.Error().Err(err).Msg("Scan Enqueue Failed")
 		writer.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	writer.WriteHeader(http.StatusOK)
-	logger.Info().
-		Str("path", scans[0].Folder).
-		Str("event", event.Type).
-		Msg("Scan Enqueued")
+	for _, scan := range scans {
+		logger.Info().
+			Str("path", scan.Folder).
+			Str("event", event.Type).
+			Msg("Scan Enqueued")
+	}
+}
+
+// pathsForDownload returns the folder→files mapping of all imported track files.
+func pathsForDownload(event *lidarrEvent) (map[string][]string, error) {
+	files := make([]string, 0, len(event.Files))
+	for _, f := range event.Files {
+		files = append(files, f.Path)
+	}
+
+	paths := arr.FilePaths(files...)
+	if len(paths) == 0 {
+		return nil, errors.New("required fields missing")
+	}
+
+	return paths, nil
+}
+
+// pathsForTrackFileDelete returns the folder→file mapping of the deleted track file.
+func pathsForTrackFileDelete(event *lidarrEvent) (map[string][]string, error) {
+	if event.File.Path == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	return arr.FilePaths(event.File.Path), nil
+}
+
+// pathsForArtist returns the artist folder for AlbumDelete and ArtistDelete events.
+func pathsForArtist(event *lidarrEvent) (map[string][]string, error) {
+	if event.Artist.Path == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	return map[string][]string{event.Artist.Path: nil}, nil
+}
+
+// pathsForRename returns all affected folder→file mappings for a Rename event.
+// Both previous and current paths are included; duplicates are dropped.
+// Without renamed track files, the artist folder is scanned as a whole.
+func pathsForRename(event *lidarrEvent) (map[string][]string, error) {
+	files := make([]arr.RenamedFile, 0, len(event.RenamedFiles))
+	for _, renamedFile := range event.RenamedFiles {
+		files = append(files, arr.RenamedFile{
+			PreviousPath: renamedFile.PreviousPath,
+			Path:         renamedFile.Path,
+		})
+	}
+
+	paths := arr.RenamedPaths(files)
+
+	if len(paths) == 0 {
+		return pathsForArtist(event)
+	}
+
+	return paths, nil
 }
 
 var now = time.Now
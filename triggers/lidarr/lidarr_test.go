@@ -6,12 +6,34 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/cloudbox/autoscan"
 )
 
+func scansEqual(expected, actual []autoscan.Scan) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	// Sort both slices for comparison
+	sortScans := func(scans []autoscan.Scan) []autoscan.Scan {
+		sorted := make([]autoscan.Scan, len(scans))
+		copy(sorted, scans)
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].Folder != sorted[j].Folder {
+				return sorted[i].Folder < sorted[j].Folder
+			}
+			return sorted[i].RelativePath < sorted[j].RelativePath
+		})
+		return sorted
+	}
+
+	return reflect.DeepEqual(sortScans(expected), sortScans(actual))
+}
+
 func TestHandler(t *testing.T) {
 	type Given struct {
 		Config  Config
@@ -50,37 +72,200 @@ func TestHandler(t *testing.T) {
 				Config:  standardConfig,
 				Fixture: "testdata/marshmello.json",
 			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Music/Marshmello/Joytime III (2019)",
+						RelativePath: "01 - Down.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/Marshmello/Joytime III (2019)",
+						RelativePath: "02 - Run It Up.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/Marshmello/Joytime III (2019)",
+						RelativePath: "03 - Put Yo Hands Up.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/Marshmello/Joytime III (2019)",
+						RelativePath: "04 - Let’s Get Down.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Multiple folders",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/blink-182.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink‐182/California (2016)/CD 01",
+						RelativePath: "01 - Cynical.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink‐182/California (2016)/CD 01",
+						RelativePath: "02 - Bored to Death.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink‐182/California (2016)/CD 02",
+						RelativePath: "01 - Parking Lot.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink‐182/California (2016)/CD 02",
+						RelativePath: "02 - Misery.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Scans album folder on TrackFileDelete event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/track_file_delete.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{{
+					Folder:       "/mnt/unionfs/Media/Music/Marshmello/Joytime III (2019)",
+					RelativePath: "01 - Down.mp3",
+					Priority:     5,
+					Time:         currentTime.Unix(),
+				}},
+			},
+		},
+		{
+			"Scans artist folder on AlbumDelete event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/album_delete.json",
+			},
 			Expected{
 				StatusCode: 200,
 				Scans: []autoscan.Scan{{
-					Folder:   "/mnt/unionfs/Media/Music/Marshmello/Joytime III (2019)",
+					Folder:   "/mnt/unionfs/Media/Music/Marshmello",
 					Priority: 5,
 					Time:     currentTime.Unix(),
 				}},
 			},
 		},
 		{
-			"Multiple folders",
+			"Scans artist folder on ArtistDelete event",
 			Given{
 				Config:  standardConfig,
-				Fixture: "testdata/blink-182.json",
+				Fixture: "testdata/artist_delete.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{{
+					Folder:   "/mnt/unionfs/Media/Music/blink-182",
+					Priority: 5,
+					Time:     currentTime.Unix(),
+				}},
+			},
+		},
+		{
+			"Scans previous and current folders on Rename event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/rename.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink182/California",
+						RelativePath: "01 - Cynical.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink182/California",
+						RelativePath: "02 - Bored to Death.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink182/California",
+						RelativePath: "13 - Parking Lot.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink-182/California (2016)/CD 01",
+						RelativePath: "01 - Cynical.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink-182/California (2016)/CD 01",
+						RelativePath: "02 - Bored to Death.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Music/blink-182/California (2016)/CD 02",
+						RelativePath: "01 - Parking Lot.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Scans the known paths on Rename event with missing paths",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/rename_partial.json",
 			},
 			Expected{
 				StatusCode: 200,
 				Scans: []autoscan.Scan{
 					{
-						Folder:   "/mnt/unionfs/Media/Music/blink‐182/California (2016)/CD 01",
-						Priority: 5,
-						Time:     currentTime.Unix(),
+						Folder:       "/mnt/unionfs/Media/Music/blink-182/California (2016)/CD 01",
+						RelativePath: "01 - Cynical.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
 					},
 					{
-						Folder:   "/mnt/unionfs/Media/Music/blink‐182/California (2016)/CD 02",
-						Priority: 5,
-						Time:     currentTime.Unix(),
+						Folder:       "/mnt/unionfs/Media/Music/blink182/California",
+						RelativePath: "02 - Bored to Death.mp3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
 					},
 				},
 			},
 		},
+		{
+			"Returns bad request on ArtistDelete event without artist path",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/artist_delete_invalid.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
 		{
 			"Returns bad request on invalid JSON",
 			Given{
@@ -106,7 +291,7 @@ func TestHandler(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.Name, func(t *testing.T) {
 			callback := func(scans ...autoscan.Scan) error {
-				if !reflect.DeepEqual(tc.Expected.Scans, scans) {
+				if !scansEqual(tc.Expected.Scans, scans) {
 					t.Logf("want: %v", tc.Expected.Scans)
 					t.Logf("got:  %v", scans)
 					t.Error("Scans do not equal")
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS poll_folder (
+    "root" TEXT NOT NULL,
+    "path" TEXT NOT NULL,
+    "mtime" INTEGER NOT NULL,
+    "entries" TEXT NOT NULL,
+    PRIMARY KEY(root, path)
+);
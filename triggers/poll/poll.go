@@ -0,0 +1,264 @@
+// Package poll provides an autoscan trigger which periodically walks the filesystem for changes.
+package poll
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/snapshot"
+)
+
+const defaultInterval = 5 * time.Minute
+
+// Config holds configuration for the poll trigger.
+type Config struct {
+	Priority  int                `yaml:"priority"`
+	Interval  time.Duration      `yaml:"interval"`
+	Depth     int                `yaml:"depth"`
+	Verbosity string             `yaml:"verbosity"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Include   []string           `yaml:"include"`
+	Exclude   []string           `yaml:"exclude"`
+	Paths     []PathConfig       `yaml:"paths"`
+}
+
+// PathConfig holds per-path overrides for the poll trigger.
+type PathConfig struct {
+	Path    string             `yaml:"path"`
+	Depth   int                `yaml:"depth"`
+	Rewrite []autoscan.Rewrite `yaml:"rewrite"`
+	Include []string           `yaml:"include"`
+	Exclude []string           `yaml:"exclude"`
+}
+
+type root struct {
+	Path     string
+	Depth    int // 0 walks all subdirectories
+	Rewriter autoscan.Rewriter
+	Allowed  autoscan.Filterer
+}
+
+type daemon struct {
+	callback autoscan.ProcessorFunc
+	priority int
+	roots    []root
+	store    *datastore
+	log      zerolog.Logger
+}
+
+// New creates a poll-based autoscan trigger which walks the configured paths
+// at every interval and stores a snapshot of their folders in the database.
+func New(cfg Config, db *sql.DB) (autoscan.Trigger, error) {
+	logger := autoscan.GetLogger(cfg.Verbosity).With().
+		Str("trigger", "poll").
+		Logger()
+
+	store, err := newDatastore(db)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, autoscan.ErrFatal)
+	}
+
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	var roots []root
+	for _, pathConfig := range cfg.Paths {
+		if pathConfig.Path == "" {
+			return nil, errors.New("path missing")
+		}
+
+		rewriter, err := autoscan.NewRewriter(append(pathConfig.Rewrite, cfg.Rewrite...))
+		if err != nil {
+			return nil, fmt.Errorf("create path rewriter: %w", err)
+		}
+
+		includes := append(pathConfig.Include, cfg.Include...)
+		excludes := append(pathConfig.Exclude, cfg.Exclude...)
+		filterer, err := autoscan.NewFilterer(includes, excludes)
+		if err != nil {
+			return nil, fmt.Errorf("create path filterer: %w", err)
+		}
+
+		depth := cfg.Depth
+		if pathConfig.Depth > 0 {
+			depth = pathConfig.Depth
+		}
+
+		roots = append(roots, root{
+			Path:     filepath.Clean(pathConfig.Path),
+			Depth:    depth,
+			Rewriter: rewriter,
+			Allowed:  filterer,
+		})
+	}
+
+	trigger := func(callback autoscan.ProcessorFunc) {
+		d := &daemon{
+			log:      logger,
+			callback: callback,
+			priority: cfg.Priority,
+			roots:    roots,
+			store:    store,
+		}
+
+		go d.run(interval)
+	}
+
+	return trigger, nil
+}
+
+func (d *daemon) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, r := range d.roots {
+			if err := d.poll(r); err != nil {
+				d.log.Error().
+					Err(err).
+					Str("path", r.Path).
+					Msg("Poll Failed")
+			}
+		}
+
+		<-ticker.C
+	}
+}
+
+// poll walks the root and enqueues a scan for every folder which changed
+// since the previous poll. The first poll of a root only stores its snapshot.
+func (d *daemon) poll(r root) error {
+	prev, err := d.store.Snapshot(r.Path)
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+
+	current, err := d.walk(r, prev)
+	if err != nil {
+		return err
+	}
+
+	if len(prev) == 0 {
+		var folders []snapshot.Folder
+		for _, f := range current {
+			folders = append(folders, f)
+		}
+
+		if err := d.store.Update(r.Path, folders, nil); err != nil {
+			return fmt.Errorf("update snapshot: %w", err)
+		}
+
+		d.log.Info().
+			Str("path", r.Path).
+			Int("folders", len(folders)).
+			Msg("Snapshot Created")
+		return nil
+	}
+
+	// An unmounted remote usually shows up as an empty directory,
+	// which would otherwise remove and later re-add the whole snapshot.
+	if len(current[r.Path].Entries) == 0 && len(prev[r.Path].Entries) > 0 {
+		d.log.Warn().
+			Str("path", r.Path).
+			Msg("Path Empty")
+		return nil
+	}
+
+	paths, updated, removed := snapshot.Diff(prev, current)
+
+	var scans []autoscan.Scan
+	for _, path := range paths {
+		rewritten := r.Rewriter(path)
+		if !r.Allowed(rewritten) {
+			continue
+		}
+
+		scans = append(scans, autoscan.Scan{
+			Folder:   filepath.Clean(rewritten),
+			Priority: d.priority,
+			Time:     now().Unix(),
+		})
+	}
+
+	if len(scans) > 0 {
+		// the snapshot is not updated, so the changes are retried at the next poll
+		if err := d.callback(scans...); err != nil {
+			return fmt.Errorf("enqueue scans: %w", err)
+		}
+
+		for _, scan := range scans {
+			d.log.Info().
+				Str("path", scan.Folder).
+				Msg("Scan Enqueued")
+		}
+	}
+
+	if err := d.store.Update(r.Path, updated, removed); err != nil {
+		return fmt.Errorf("update snapshot: %w", err)
+	}
+
+	d.log.Debug().
+		Str("path", r.Path).
+		Int("folders", len(current)).
+		Int("changed", len(paths)).
+		Msg("Poll Completed")
+
+	return nil
+}
+
+// walk returns the current snapshot of the folders below the root, up to the depth limit.
+// Folders which cannot be read keep their previous snapshot.
+func (d *daemon) walk(r root, prev map[string]snapshot.Folder) (map[string]snapshot.Folder, error) {
+	current := make(map[string]snapshot.Folder)
+
+	// the root itself must be readable
+	rootFolder, rootSubdirs, err := snapshot.Read(r.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read root: %w", err)
+	}
+
+	var visit func(f snapshot.Folder, subdirs []string, level int)
+	visit = func(f snapshot.Folder, subdirs []string, level int) {
+		current[f.Path] = f
+		if r.Depth > 0 && level >= r.Depth {
+			return
+		}
+
+		for _, name := range subdirs {
+			dir := filepath.Join(f.Path, name)
+
+			sub, subdirs, err := snapshot.Read(dir)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				// removed since its parent was read
+			case err != nil:
+				d.log.Warn().
+					Err(err).
+					Str("path", dir).
+					Msg("Folder Read Failed")
+
+				for path, p := range prev {
+					if snapshot.Within(path, dir) {
+						current[path] = p
+					}
+				}
+			default:
+				visit(sub, subdirs, level+1)
+			}
+		}
+	}
+
+	visit(rootFolder, rootSubdirs, 0)
+	return current, nil
+}
+
+var now = time.Now
@@ -0,0 +1,253 @@
+package poll
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/sqlite"
+)
+
+func getDaemon(t *testing.T, cfg Config) (*daemon, *[]autoscan.Scan) {
+	t.Helper()
+
+	db, err := sqlite.NewDB(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	store, err := newDatastore(db.RW())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var scans []autoscan.Scan
+	d := &daemon{
+		callback: func(s ...autoscan.Scan) error {
+			scans = append(scans, s...)
+			return nil
+		},
+		priority: cfg.Priority,
+		store:    store,
+		log:      autoscan.GetLogger(""),
+	}
+
+	for _, p := range cfg.Paths {
+		rewriter, err := autoscan.NewRewriter(append(p.Rewrite, cfg.Rewrite...))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		filterer, err := autoscan.NewFilterer(append(p.Include, cfg.Include...), append(p.Exclude, cfg.Exclude...))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		depth := cfg.Depth
+		if p.Depth > 0 {
+			depth = p.Depth
+		}
+
+		d.roots = append(d.roots, root{Path: p.Path, Depth: depth, Rewriter: rewriter, Allowed: filterer})
+	}
+
+	return d, &scans
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	currentTime := time.Now()
+	now = func() time.Time {
+		return currentTime
+	}
+
+	type Test struct {
+		Name     string
+		Config   func(dir string) Config
+		Change   func(t *testing.T, dir string)
+		Expected []string // scanned folders, relative to /mnt/unionfs/Media
+	}
+
+	defaultConfig := func(dir string) Config {
+		return Config{
+			Priority: 2,
+			Exclude:  []string{"/Sample$"},
+			Paths: []PathConfig{{
+				Path: dir,
+				Rewrite: []autoscan.Rewrite{{
+					From: "^" + dir,
+					To:   "/mnt/unionfs/Media",
+				}},
+			}},
+		}
+	}
+
+	testCases := []Test{
+		{
+			Name:   "Nothing changed",
+			Config: defaultConfig,
+			Change: func(t *testing.T, dir string) {},
+		},
+		{
+			Name:   "File added",
+			Config: defaultConfig,
+			Change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "Movies/Tenet (2020)/Tenet.srt"), "subtitles")
+			},
+			Expected: []string{"Movies/Tenet (2020)"},
+		},
+		{
+			Name:   "File replaced",
+			Config: defaultConfig,
+			Change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "Movies/Tenet (2020)/Tenet.mkv"), "upgraded movie")
+			},
+			Expected: []string{"Movies/Tenet (2020)"},
+		},
+		{
+			Name:   "Folder added",
+			Config: defaultConfig,
+			Change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "Movies/Dune (2021)/Dune.mkv"), "movie")
+			},
+			Expected: []string{"Movies/Dune (2021)"},
+		},
+		{
+			Name:   "Folder removed",
+			Config: defaultConfig,
+			Change: func(t *testing.T, dir string) {
+				if err := os.RemoveAll(filepath.Join(dir, "Movies/Tenet (2020)")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			Expected: []string{"Movies"},
+		},
+		{
+			Name:   "Excluded folder",
+			Config: defaultConfig,
+			Change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "Movies/Tenet (2020)/Sample/Tenet.sample.mkv"), "sample")
+			},
+		},
+		{
+			Name: "File changed below the depth limit",
+			Config: func(dir string) Config {
+				cfg := defaultConfig(dir)
+				cfg.Depth = 2
+				return cfg
+			},
+			Change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "Movies/Tenet (2020)/Subs/Tenet.en.srt"), "new subtitles")
+			},
+		},
+		{
+			Name: "Folder added below the depth limit",
+			Config: func(dir string) Config {
+				cfg := defaultConfig(dir)
+				cfg.Depth = 2
+				return cfg
+			},
+			Change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "Movies/Tenet (2020)/Featurettes/Tenet.mkv"), "featurette")
+			},
+			Expected: []string{"Movies/Tenet (2020)"},
+		},
+		{
+			Name:   "Root emptied",
+			Config: defaultConfig,
+			Change: func(t *testing.T, dir string) {
+				if err := os.RemoveAll(filepath.Join(dir, "Movies")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "Movies/Tenet (2020)/Tenet.mkv"), "movie")
+			writeFile(t, filepath.Join(dir, "Movies/Tenet (2020)/Subs/Tenet.en.srt"), "subtitles")
+
+			d, scans := getDaemon(t, tc.Config(dir))
+
+			// the first poll only creates the snapshot
+			if err := d.poll(d.roots[0]); err != nil {
+				t.Fatal(err)
+			}
+			if len(*scans) != 0 {
+				t.Fatalf("expected no scans on the first poll, got %v", *scans)
+			}
+
+			tc.Change(t, dir)
+
+			if err := d.poll(d.roots[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			var expected []autoscan.Scan
+			for _, folder := range tc.Expected {
+				expected = append(expected, autoscan.Scan{
+					Folder:   filepath.Join("/mnt/unionfs/Media", folder),
+					Priority: 2,
+					Time:     currentTime.Unix(),
+				})
+			}
+
+			if !reflect.DeepEqual(expected, *scans) {
+				t.Log(*scans)
+				t.Log(expected)
+				t.Error("Scans do not equal")
+			}
+		})
+	}
+}
+
+func TestPollRetriesFailedEnqueue(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Tenet (2020)/Tenet.mkv"), "movie")
+
+	d, scans := getDaemon(t, Config{Paths: []PathConfig{{Path: dir}}})
+	if err := d.poll(d.roots[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(dir, "Dune (2021)/Dune.mkv"), "movie")
+
+	callback := d.callback
+	d.callback = func(...autoscan.Scan) error {
+		return autoscan.ErrFatal
+	}
+
+	if err := d.poll(d.roots[0]); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	d.callback = callback
+	if err := d.poll(d.roots[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*scans) != 1 || (*scans)[0].Folder != filepath.Join(dir, "Dune (2021)") {
+		t.Errorf("expected a scan of the new folder, got %v", *scans)
+	}
+}
@@ -3,6 +3,7 @@ package radarr
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"path"
@@ -12,6 +13,7 @@ import (
 	"github.com/rs/zerolog/hlog"
 
 	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/arr"
 )
 
 // Config holds configuration for the Radarr trigger.
@@ -54,19 +56,24 @@ type radarrMovie struct {
 	FolderPath string `json:"folderPath"`
 }
 
+type radarrRenamedFile struct {
+	// use PreviousPath as the Movie.FolderPath might have changed.
+	PreviousPath string `json:"previousPath"`
+	RelativePath string `json:"relativePath"`
+}
+
 type radarrEvent struct {
-	Type  string      `json:"eventType"`
-	File  radarrFile  `json:"movieFile"`
-	Movie radarrMovie `json:"movie"`
+	Type         string              `json:"eventType"`
+	File         radarrFile          `json:"movieFile"`
+	Movie        radarrMovie         `json:"movie"`
+	RenamedFiles []radarrRenamedFile `json:"renamedMovieFiles"`
 }
 
 func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
-	var err error
 	rlog := hlog.FromRequest(r)
 
 	event := new(radarrEvent)
-	err = json.NewDecoder(r.Body).Decode(event)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(event); err != nil {
 		rlog.Error().Err(err).Msg("Request Decode Failed")
 		writer.WriteHeader(http.StatusBadRequest)
 		return
@@ -81,51 +88,100 @@ func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
 	}
 
 	var (
-		folderPath string
-		filePath   string
+		paths map[string][]string
+		err   error
 	)
 
-	if strings.EqualFold(event.Type, "Download") || strings.EqualFold(event.Type, "MovieFileDelete") {
-		if event.File.RelativePath == "" || event.Movie.FolderPath == "" {
-			rlog.Error().Msg("Required Fields Missing")
-			writer.WriteHeader(http.StatusBadRequest)
-			return
-		}
+	switch {
+	case strings.EqualFold(event.Type, "Download") || strings.EqualFold(event.Type, "MovieFileDelete"):
+		paths, err = pathsForDownload(event)
+	case strings.EqualFold(event.Type, "MovieDelete"):
+		paths, err = pathsForMovieDelete(event)
+	case strings.EqualFold(event.Type, "Rename"):
+		paths, err = pathsForRename(event)
+	default:
+		// unknown event type — nothing to scan
+	}
 
-		folderPath = path.Dir(path.Join(event.Movie.FolderPath, event.File.RelativePath))
-		filePath = path.Base(path.Join(event.Movie.FolderPath, event.File.RelativePath))
+	if err != nil {
+		rlog.Error().Err(err).Msg("Required Fields Missing")
+		writer.WriteHeader(http.StatusBadRequest)
+		return
 	}
 
-	if strings.EqualFold(event.Type, "MovieDelete") || strings.EqualFold(event.Type, "Rename") {
-		if event.Movie.FolderPath == "" {
-			rlog.Error().Msg("Required Fields Missing")
-			writer.WriteHeader(http.StatusBadRequest)
-			return
-		}
+	var scans []autoscan.Scan
 
-		folderPath = event.Movie.FolderPath
-	}
+	for folderPath, files := range paths {
+		if len(files) == 0 {
+			// scan the folder as a whole
+			files = []string{""}
+		}
 
-	scan := autoscan.Scan{
-		Folder:       h.rewrite(folderPath),
-		RelativePath: filePath,
-		Priority:     h.priority,
-		Time:         now().Unix(),
+		for _, filePath := range files {
+			scans = append(scans, autoscan.Scan{
+				Folder:       h.rewrite(folderPath),
+				RelativePath: filePath,
+				Priority:     h.priority,
+				Time:         now().Unix(),
+			})
+		}
 	}
 
-	err = h.callback(scan)
-	if err != nil {
+	if err = h.callback(scans...); err != nil {
 		rlog.Error().Err(err).Msg("Scan Enqueue Failed")
 		writer.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	rlog.Info().
-		Str("path", folderPath).
-		Str("event", event.Type).
-		Msg("Scan Enqueued")
+	for _, scan := range scans {
+		rlog.Info().
+			Str("path", scan.Folder).
+			Str("event", event.Type).
+			Msg("Scan Enqueued")
+	}
 
 	writer.WriteHeader(http.StatusOK)
 }
 
+// pathsForDownload returns the folder→file mapping for Download and MovieFileDelete events.
+func pathsForDownload(event *radarrEvent) (map[string][]string, error) {
+	if event.File.RelativePath == "" || event.Movie.FolderPath == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	return arr.FilePaths(path.Join(event.Movie.FolderPath, event.File.RelativePath)), nil
+}
+
+// pathsForMovieDelete returns the movie folder for a MovieDelete event.
+func pathsForMovieDelete(event *radarrEvent) (map[string][]string, error) {
+	if event.Movie.FolderPath == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	return map[string][]string{event.Movie.FolderPath: nil}, nil
+}
+
+// pathsForRename returns all affected folder→file mappings for a Rename event.
+// Both previous and current paths are included; duplicates are dropped.
+// Without renamed files, the movie folder is scanned as a whole.
+func pathsForRename(event *radarrEvent) (map[string][]string, error) {
+	if event.Movie.FolderPath == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	if len(event.RenamedFiles) == 0 {
+		return map[string][]string{event.Movie.FolderPath: nil}, nil
+	}
+
+	files := make([]arr.RenamedFile, 0, len(event.RenamedFiles))
+	for _, renamedFile := range event.RenamedFiles {
+		files = append(files, arr.RenamedFile{
+			PreviousPath: renamedFile.PreviousPath,
+			Path:         path.Join(event.Movie.FolderPath, renamedFile.RelativePath),
+		})
+	}
+
+	return arr.RenamedPaths(files), nil
+}
+
 var now = time.Now
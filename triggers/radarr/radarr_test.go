@@ -6,12 +6,34 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/cloudbox/autoscan"
 )
 
+func scansEqual(expected, actual []autoscan.Scan) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	// Sort both slices for comparison
+	sortScans := func(scans []autoscan.Scan) []autoscan.Scan {
+		sorted := make([]autoscan.Scan, len(scans))
+		copy(sorted, scans)
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].Folder != sorted[j].Folder {
+				return sorted[i].Folder < sorted[j].Folder
+			}
+			return sorted[i].RelativePath < sorted[j].RelativePath
+		})
+		return sorted
+	}
+
+	return reflect.DeepEqual(sortScans(expected), sortScans(actual))
+}
+
 func TestHandler(t *testing.T) {
 	type Given struct {
 		Config  Config
@@ -98,7 +120,7 @@ func TestHandler(t *testing.T) {
 			},
 		},
 		{
-			"Rename Event",
+			"Rename Event without renamed files",
 			Given{
 				Config:  standardConfig,
 				Fixture: "testdata/rename.json",
@@ -114,6 +136,52 @@ func TestHandler(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Rename Event scans previous and current folders",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/rename_files.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Movies/Deadpool (2016)",
+						RelativePath: "Deadpool.2016.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Movies/Deadpool (2016)",
+						RelativePath: "Deadpool.2016.Extras.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Movies/Deadpool (2016) {tmdb-293660}",
+						RelativePath: "Deadpool (2016) {tmdb-293660} [Bluray-1080p].mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Movies/Deadpool (2016) {tmdb-293660}",
+						RelativePath: "Deadpool (2016) {tmdb-293660} [Bluray-1080p]-extras.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Returns bad request on Rename Event without movie folder",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/rename_invalid.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
 		{
 			"Returns bad request on invalid JSON",
 			Given{
@@ -139,7 +207,7 @@ func TestHandler(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.Name, func(t *testing.T) {
 			callback := func(scans ...autoscan.Scan) error {
-				if !reflect.DeepEqual(tc.Expected.Scans, scans) {
+				if !scansEqual(tc.Expected.Scans, scans) {
 					t.Log(scans)
 					t.Log(tc.Expected.Scans)
 					t.Error("Scans do not equal")
@@ -0,0 +1,129 @@
+package rclone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// rcTimeout is generous, as a recursive listing of a large remote takes a while.
+const rcTimeout = 10 * time.Minute
+
+type apiClient struct {
+	client  *http.Client
+	baseURL string
+	user    string
+	pass    string
+}
+
+func newAPIClient(baseURL, user, pass string) apiClient {
+	return apiClient{
+		client:  &http.Client{Timeout: rcTimeout},
+		baseURL: baseURL,
+		user:    user,
+		pass:    pass,
+	}
+}
+
+// call invokes a remote control command and decodes its response into result.
+func (c apiClient) call(command string, params, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("encode params: %w", err)
+	}
+
+	reqURL := autoscan.JoinURL(c.baseURL, command)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed creating %s request: %w", command, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.user != "" && c.pass != "" {
+		req.SetBasicAuth(c.user, c.pass)
+	}
+
+	res, err := c.client.Do(req) //nolint:gosec // URL is user-configured in app config, SSRF is intentional
+	if err != nil {
+		return fmt.Errorf("%s: %w", command, err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		// rclone describes the error in the response body
+		rcErr := new(struct {
+			Error string `json:"error"`
+		})
+
+		if err := json.NewDecoder(autoscan.LimitReadCloser(res.Body)).Decode(rcErr); err != nil || rcErr.Error == "" {
+			return fmt.Errorf("%s: %s", command, res.Status)
+		}
+
+		return fmt.Errorf("%s: %s: %s", command, res.Status, rcErr.Error)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	// a recursive listing can exceed the response size limit of the targets
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("decode %s response: %w", command, err)
+	}
+
+	return nil
+}
+
+type listItem struct {
+	Path    string `json:"Path"`
+	Name    string `json:"Name"`
+	Size    int64  `json:"Size"`
+	ModTime string `json:"ModTime"`
+	IsDir   bool   `json:"IsDir"`
+}
+
+// List recursively lists the path of the remote.
+func (c apiClient) List(fs, remote string) ([]listItem, error) {
+	params := map[string]any{
+		"fs":     fs,
+		"remote": remote,
+		"opt": map[string]any{
+			"recurse":    true,
+			"noMimeType": true,
+		},
+	}
+
+	resp := new(struct {
+		List []listItem `json:"list"`
+	})
+
+	if err := c.call("operations/list", params, resp); err != nil {
+		return nil, err
+	}
+
+	return resp.List, nil
+}
+
+// Refresh refreshes the directory cache of the mounted remote for the given directories.
+func (c apiClient) Refresh(fs string, dirs []string) error {
+	params := map[string]any{
+		"fs": fs,
+	}
+
+	for i, dir := range dirs {
+		key := "dir"
+		if i > 0 {
+			key = fmt.Sprintf("dir%d", i+1)
+		}
+
+		params[key] = dir
+	}
+
+	return c.call("vfs/refresh", params, nil)
+}
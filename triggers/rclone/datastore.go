@@ -0,0 +1,115 @@
+package rclone
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudbox/autoscan/internal/snapshot"
+	"github.com/cloudbox/autoscan/migrate"
+)
+
+type datastore struct {
+	db *sql.DB
+}
+
+//go:embed migrations
+var migrations embed.FS
+
+func newDatastore(db *sql.DB) (*datastore, error) {
+	mg, err := migrate.New(db, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("create migrator: %w", err)
+	}
+
+	if err := mg.Migrate(&migrations, "rclone"); err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	return &datastore{db: db}, nil
+}
+
+const sqlSelectFolders = `SELECT path, entries FROM rclone_folder WHERE remote = ?`
+
+// Snapshot returns the folders of the remote as seen by the previous listing.
+func (store *datastore) Snapshot(remote string) (map[string]snapshot.Folder, error) {
+	rows, err := store.db.QueryContext(context.Background(), sqlSelectFolders, remote)
+	if err != nil {
+		return nil, fmt.Errorf("select folders: %w", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	folders := make(map[string]snapshot.Folder)
+	for rows.Next() {
+		var (
+			f       snapshot.Folder
+			entries string
+		)
+
+		if err := rows.Scan(&f.Path, &entries); err != nil {
+			return nil, fmt.Errorf("scan folder row: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(entries), &f.Entries); err != nil {
+			return nil, fmt.Errorf("decode entries: %v: %w", f.Path, err)
+		}
+
+		folders[f.Path] = f
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate folders: %w", err)
+	}
+
+	return folders, nil
+}
+
+const sqlUpsertFolder = `
+INSERT INTO rclone_folder (remote, path, entries)
+VALUES (?, ?, ?)
+ON CONFLICT (remote, path) DO UPDATE SET
+	entries = excluded.entries
+`
+
+const sqlDeleteFolder = `DELETE FROM rclone_folder WHERE remote = ? AND path = ?`
+
+// Update stores the changed folders of the remote and forgets the removed ones.
+func (store *datastore) Update(remote string, changed []snapshot.Folder, removed []string) (err error) {
+	tx, err := store.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, f := range changed {
+		entries, err := json.Marshal(f.Entries)
+		if err != nil {
+			return fmt.Errorf("encode entries: %v: %w", f.Path, err)
+		}
+
+		_, err = tx.ExecContext(context.Background(), sqlUpsertFolder, remote, f.Path, string(entries))
+		if err != nil {
+			return fmt.Errorf("exec upsert folder: %w", err)
+		}
+	}
+
+	for _, path := range removed {
+		if _, err := tx.ExecContext(context.Background(), sqlDeleteFolder, remote, path); err != nil {
+			return fmt.Errorf("exec delete folder: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS rclone_folder (
+    "remote" TEXT NOT NULL,
+    "path" TEXT NOT NULL,
+    "entries" TEXT NOT NULL,
+    PRIMARY KEY(remote, path)
+);
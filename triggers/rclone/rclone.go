@@ -0,0 +1,298 @@
+// Package rclone provides an autoscan trigger which polls the remote control API of rclone for changes.
+package rclone
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/snapshot"
+)
+
+const (
+	defaultURL      = "http://localhost:5572"
+	defaultInterval = 5 * time.Minute
+)
+
+// Config holds configuration for the rclone trigger.
+type Config struct {
+	URL       string             `yaml:"url"`
+	Username  string             `yaml:"username"`
+	Password  string             `yaml:"password"` //nolint:gosec // user-provided credential field
+	Interval  time.Duration      `yaml:"interval"`
+	Priority  int                `yaml:"priority"`
+	Verbosity string             `yaml:"verbosity"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Include   []string           `yaml:"include"`
+	Exclude   []string           `yaml:"exclude"`
+	Remotes   []RemoteConfig     `yaml:"remotes"`
+}
+
+// RemoteConfig holds per-remote overrides for the rclone trigger.
+type RemoteConfig struct {
+	Fs      string             `yaml:"fs"`
+	Path    string             `yaml:"path"`
+	Refresh bool               `yaml:"refresh"`
+	Rewrite []autoscan.Rewrite `yaml:"rewrite"`
+	Include []string           `yaml:"include"`
+	Exclude []string           `yaml:"exclude"`
+}
+
+type remote struct {
+	Fs       string
+	Path     string // rooted at the remote, e.g. /Media
+	Refresh  bool
+	Rewriter autoscan.Rewriter
+	Allowed  autoscan.Filterer
+}
+
+type daemon struct {
+	callback autoscan.ProcessorFunc
+	priority int
+	remotes  []remote
+	api      apiClient
+	store    *datastore
+	log      zerolog.Logger
+}
+
+// New creates an autoscan trigger which lists the configured remotes through
+// rclone's remote control API at every interval and stores a snapshot of
+// their folders in the database.
+func New(cfg Config, db *sql.DB) (autoscan.Trigger, error) {
+	if cfg.URL == "" {
+		cfg.URL = defaultURL
+	}
+
+	logger := autoscan.GetLogger(cfg.Verbosity).With().
+		Str("trigger", "rclone").
+		Str("url", cfg.URL).
+		Logger()
+
+	store, err := newDatastore(db)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, autoscan.ErrFatal)
+	}
+
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	var remotes []remote
+	for _, remoteCfg := range cfg.Remotes {
+		if remoteCfg.Fs == "" {
+			return nil, errors.New("remote fs missing")
+		}
+
+		rewriter, err := autoscan.NewRewriter(append(remoteCfg.Rewrite, cfg.Rewrite...))
+		if err != nil {
+			return nil, fmt.Errorf("create remote rewriter: %w", err)
+		}
+
+		includes := append(remoteCfg.Include, cfg.Include...)
+		excludes := append(remoteCfg.Exclude, cfg.Exclude...)
+		filterer, err := autoscan.NewFilterer(includes, excludes)
+		if err != nil {
+			return nil, fmt.Errorf("create remote filterer: %w", err)
+		}
+
+		remotes = append(remotes, remote{
+			Fs:       remoteCfg.Fs,
+			Path:     path.Join("/", remoteCfg.Path),
+			Refresh:  remoteCfg.Refresh,
+			Rewriter: rewriter,
+			Allowed:  filterer,
+		})
+	}
+
+	trigger := func(callback autoscan.ProcessorFunc) {
+		d := &daemon{
+			log:      logger,
+			callback: callback,
+			priority: cfg.Priority,
+			remotes:  remotes,
+			api:      newAPIClient(cfg.URL, cfg.Username, cfg.Password),
+			store:    store,
+		}
+
+		go d.run(interval)
+	}
+
+	return trigger, nil
+}
+
+func (d *daemon) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, r := range d.remotes {
+			if err := d.poll(r); err != nil {
+				d.log.Error().
+					Err(err).
+					Str("fs", r.Fs).
+					Str("path", r.Path).
+					Msg("Poll Failed")
+			}
+		}
+
+		<-ticker.C
+	}
+}
+
+// poll lists the remote and enqueues a scan for every folder which changed
+// since the previous listing. The first listing of a remote is only stored.
+func (d *daemon) poll(r remote) error {
+	key := r.Fs + r.Path
+	prev, err := d.store.Snapshot(key)
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+
+	items, err := d.api.List(r.Fs, strings.TrimPrefix(r.Path, "/"))
+	if err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+
+	current := folders(r.Path, items)
+
+	if len(prev) == 0 {
+		seed := make([]snapshot.Folder, 0, len(current))
+		for _, f := range current {
+			seed = append(seed, f)
+		}
+
+		if err := d.store.Update(key, seed, nil); err != nil {
+			return fmt.Errorf("update snapshot: %w", err)
+		}
+
+		d.log.Info().
+			Str("fs", r.Fs).
+			Str("path", r.Path).
+			Int("folders", len(seed)).
+			Msg("Snapshot Created")
+		return nil
+	}
+
+	// A remote which is suddenly empty is most likely misbehaving.
+	if len(current[r.Path].Entries) == 0 && len(prev[r.Path].Entries) > 0 {
+		d.log.Warn().
+			Str("fs", r.Fs).
+			Str("path", r.Path).
+			Msg("Remote Empty")
+		return nil
+	}
+
+	paths, updated, removed := snapshot.Diff(prev, current)
+
+	if r.Refresh && len(paths) > 0 {
+		if err := d.api.Refresh(r.Fs, refreshDirs(paths, prev)); err != nil {
+			// the targets pick up the changes once the directory cache expires
+			d.log.Warn().
+				Err(err).
+				Str("fs", r.Fs).
+				Msg("VFS Refresh Failed")
+		}
+	}
+
+	var scans []autoscan.Scan
+	for _, p := range paths {
+		rewritten := r.Rewriter(p)
+		if !r.Allowed(rewritten) {
+			continue
+		}
+
+		scans = append(scans, autoscan.Scan{
+			Folder:   path.Clean(rewritten),
+			Priority: d.priority,
+			Time:     now().Unix(),
+		})
+	}
+
+	if len(scans) > 0 {
+		// the snapshot is not updated, so the changes are retried at the next poll
+		if err := d.callback(scans...); err != nil {
+			return fmt.Errorf("enqueue scans: %w", err)
+		}
+
+		for _, scan := range scans {
+			d.log.Info().
+				Str("path", scan.Folder).
+				Msg("Scan Enqueued")
+		}
+	}
+
+	if err := d.store.Update(key, updated, removed); err != nil {
+		return fmt.Errorf("update snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// folders returns the snapshot of the folders in the recursive listing of root.
+// Listed paths are relative to the root of the remote.
+func folders(root string, items []listItem) map[string]snapshot.Folder {
+	current := map[string]snapshot.Folder{
+		root: {Path: root},
+	}
+
+	add := func(dir, entry string) {
+		f := current[dir]
+		f.Path = dir
+		f.Entries = append(f.Entries, entry)
+		current[dir] = f
+	}
+
+	for _, item := range items {
+		p := path.Join("/", item.Path)
+		if item.IsDir {
+			if _, ok := current[p]; !ok {
+				current[p] = snapshot.Folder{Path: p}
+			}
+
+			add(path.Dir(p), snapshot.DirEntry(item.Name))
+			continue
+		}
+
+		var modTime int64
+		if t, err := time.Parse(time.RFC3339Nano, item.ModTime); err == nil {
+			modTime = t.UnixNano()
+		}
+
+		add(path.Dir(p), snapshot.FileEntry(item.Name, item.Size, modTime))
+	}
+
+	for dir, f := range current {
+		slices.Sort(f.Entries)
+		current[dir] = f
+	}
+
+	return current
+}
+
+// refreshDirs returns the directories of the mount to refresh, relative to
+// the root of the remote. New folders are picked up by refreshing their parent.
+func refreshDirs(paths []string, prev map[string]snapshot.Folder) []string {
+	var dirs []string
+	for _, p := range paths {
+		if _, ok := prev[p]; !ok {
+			p = path.Dir(p)
+		}
+
+		dir := strings.TrimPrefix(p, "/")
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+var now = time.Now
@@ -0,0 +1,307 @@
+package rclone
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/sqlite"
+)
+
+// rcServer stands in for the remote control API of rclone.
+type rcServer struct {
+	items     []listItem
+	refreshed []string
+	err       string
+}
+
+func (s *rcServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "rclone" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var params map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params["fs"] != "gdrive:" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if s.err != "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": s.err, "status": 500})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/operations/list":
+		if params["remote"] != "Media" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"list": s.items})
+
+	case "/vfs/refresh":
+		for key, dir := range params {
+			if key != "fs" {
+				s.refreshed = append(s.refreshed, dir.(string))
+			}
+		}
+
+		slices.Sort(s.refreshed)
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]string{}})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func dir(p string) listItem {
+	return listItem{Path: p, Name: path.Base(p), IsDir: true}
+}
+
+func file(p string, size int64) listItem {
+	return listItem{Path: p, Name: path.Base(p), Size: size, ModTime: "2024-01-02T03:04:05.123456789Z"}
+}
+
+func TestPoll(t *testing.T) {
+	currentTime := time.Now()
+	now = func() time.Time {
+		return currentTime
+	}
+
+	initial := []listItem{
+		dir("Media/Movies"),
+		dir("Media/Movies/Tenet (2020)"),
+		file("Media/Movies/Tenet (2020)/Tenet.mkv", 100),
+		dir("Media/TV"),
+	}
+
+	type Test struct {
+		Name      string
+		Items     []listItem
+		Expected  []string // scanned folders
+		Refreshed []string
+	}
+
+	testCases := []Test{
+		{
+			Name:  "Nothing changed",
+			Items: initial,
+		},
+		{
+			Name: "File added",
+			Items: append(slices.Clone(initial),
+				file("Media/Movies/Tenet (2020)/Tenet.srt", 10)),
+			Expected:  []string{"/mnt/unionfs/Media/Movies/Tenet (2020)"},
+			Refreshed: []string{"Media/Movies/Tenet (2020)"},
+		},
+		{
+			Name: "File upgraded",
+			Items: []listItem{
+				dir("Media/Movies"),
+				dir("Media/Movies/Tenet (2020)"),
+				file("Media/Movies/Tenet (2020)/Tenet.mkv", 200),
+				dir("Media/TV"),
+			},
+			Expected:  []string{"/mnt/unionfs/Media/Movies/Tenet (2020)"},
+			Refreshed: []string{"Media/Movies/Tenet (2020)"},
+		},
+		{
+			Name: "Folder added",
+			Items: append(slices.Clone(initial),
+				dir("Media/Movies/Dune (2021)"),
+				file("Media/Movies/Dune (2021)/Dune.mkv", 100)),
+			Expected:  []string{"/mnt/unionfs/Media/Movies/Dune (2021)"},
+			Refreshed: []string{"Media/Movies"},
+		},
+		{
+			Name: "Folder removed",
+			Items: []listItem{
+				dir("Media/Movies"),
+				dir("Media/TV"),
+			},
+			Expected:  []string{"/mnt/unionfs/Media/Movies"},
+			Refreshed: []string{"Media/Movies"},
+		},
+		{
+			Name: "Excluded folder",
+			Items: append(slices.Clone(initial),
+				dir("Media/TV/Westworld"),
+				file("Media/TV/Westworld/Westworld.S01E01.mkv", 100)),
+			Refreshed: []string{"Media/TV"},
+		},
+		{
+			Name: "Remote emptied",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rc := &rcServer{items: initial}
+			server := httptest.NewServer(rc)
+			defer server.Close()
+
+			trigger, scans := getDaemon(t, server.URL, getDatastore(t))
+
+			// the first listing is only remembered
+			if err := trigger.poll(trigger.remotes[0]); err != nil {
+				t.Fatal(err)
+			}
+			if len(*scans) != 0 {
+				t.Fatalf("expected no scans on the first poll, got %v", *scans)
+			}
+
+			rc.items = tc.Items
+			if err := trigger.poll(trigger.remotes[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			var expected []autoscan.Scan
+			for _, folder := range tc.Expected {
+				expected = append(expected, autoscan.Scan{
+					Folder:   folder,
+					Priority: 4,
+					Time:     currentTime.Unix(),
+				})
+			}
+
+			if !reflect.DeepEqual(expected, *scans) {
+				t.Log(*scans)
+				t.Log(expected)
+				t.Error("Scans do not equal")
+			}
+
+			if !reflect.DeepEqual(tc.Refreshed, rc.refreshed) {
+				t.Errorf("expected refreshed directories %v, got %v", tc.Refreshed, rc.refreshed)
+			}
+		})
+	}
+}
+
+func TestPollError(t *testing.T) {
+	rc := &rcServer{err: "directory not found"}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	trigger, _ := getDaemon(t, server.URL, getDatastore(t))
+
+	err := trigger.poll(trigger.remotes[0])
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	if expected := "list: operations/list: 500 Internal Server Error: directory not found"; err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+
+	prev, err := trigger.store.Snapshot("gdrive:/Media")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(prev) != 0 {
+		t.Errorf("expected no snapshot after a failed listing, got %v", prev)
+	}
+}
+
+func TestPollAfterRestart(t *testing.T) {
+	currentTime := time.Now()
+	now = func() time.Time {
+		return currentTime
+	}
+
+	rc := &rcServer{items: []listItem{
+		dir("Media/Movies"),
+		dir("Media/Movies/Tenet (2020)"),
+		file("Media/Movies/Tenet (2020)/Tenet.mkv", 100),
+	}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	store := getDatastore(t)
+
+	trigger, _ := getDaemon(t, server.URL, store)
+	if err := trigger.poll(trigger.remotes[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// changed while autoscan was not running
+	rc.items = append(rc.items, file("Media/Movies/Tenet (2020)/Tenet.srt", 10))
+
+	restarted, scans := getDaemon(t, server.URL, store)
+	if err := restarted.poll(restarted.remotes[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []autoscan.Scan{{
+		Folder:   "/mnt/unionfs/Media/Movies/Tenet (2020)",
+		Priority: 4,
+		Time:     currentTime.Unix(),
+	}}
+
+	if !reflect.DeepEqual(expected, *scans) {
+		t.Errorf("expected scans %v, got %v", expected, *scans)
+	}
+}
+
+func getDatastore(t *testing.T) *datastore {
+	t.Helper()
+
+	db, err := sqlite.NewDB(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	store, err := newDatastore(db.RW())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func getDaemon(t *testing.T, url string, store *datastore) (*daemon, *[]autoscan.Scan) {
+	t.Helper()
+
+	rewriter, _ := autoscan.NewRewriter([]autoscan.Rewrite{{
+		From: "^/Media/",
+		To:   "/mnt/unionfs/Media/",
+	}})
+
+	filterer, _ := autoscan.NewFilterer(nil, []string{"/TV/"})
+
+	var scans []autoscan.Scan
+	d := &daemon{
+		callback: func(s ...autoscan.Scan) error {
+			scans = append(scans, s...)
+			return nil
+		},
+		priority: 4,
+		api:      newAPIClient(url, "rclone", "secret"),
+		log:      autoscan.GetLogger(""),
+		remotes: []remote{{
+			Fs:       "gdrive:",
+			Path:     "/Media",
+			Refresh:  true,
+			Rewriter: rewriter,
+			Allowed:  filterer,
+		}},
+		store: store,
+	}
+
+	return d, &scans
+}
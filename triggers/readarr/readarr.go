@@ -3,15 +3,16 @@ package readarr
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"path"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/hlog"
 
 	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/arr"
 )
 
 // Config holds configuration for the Readarr trigger.
@@ -50,20 +51,31 @@ type readarrFile struct {
 	Path string `json:"path"`
 }
 
+type readarrAuthor struct {
+	Path string `json:"path"`
+}
+
+type readarrRenamedFile struct {
+	// use PreviousPath as the Author.Path might have changed.
+	PreviousPath string `json:"previousPath"`
+	Path         string `json:"path"`
+}
+
 type readarrEvent struct {
 	Type    string `json:"eventType"`
 	Upgrade bool   `json:"isUpgrade"`
 
-	Files []readarrFile `json:"bookFiles"`
+	Files        []readarrFile        `json:"bookFiles"`
+	File         readarrFile          `json:"bookFile"`
+	Author       readarrAuthor        `json:"author"`
+	RenamedFiles []readarrRenamedFile `json:"renamedBookFiles"`
 }
 
 func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
-	var err error
 	logger := hlog.FromRequest(r)
 
 	event := new(readarrEvent)
-	err = json.NewDecoder(r.Body).Decode(event)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(event); err != nil {
 		logger.Error().Err(err).Msg("Request Decode Failed")
 		writer.WriteHeader(http.StatusBadRequest)
 		return
@@ -77,43 +89,119 @@ func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only handle test and download. Everything else is ignored.
-	if !strings.EqualFold(event.Type, "Download") || len(event.Files) == 0 {
-		logger.Error().Msg("Required Fields Missing")
+	var (
+		paths map[string][]string
+		err   error
+	)
+
+	switch {
+	case strings.EqualFold(event.Type, "Download"):
+		paths, err = pathsForDownload(event)
+	case strings.EqualFold(event.Type, "BookFileDelete") || strings.EqualFold(event.Type, "Retag"):
+		paths, err = pathsForBookFile(event)
+	case strings.EqualFold(event.Type, "BookDelete") || strings.EqualFold(event.Type, "AuthorDelete"):
+		// Readarr does not send the book folder, so the whole author is scanned.
+		paths, err = pathsForAuthor(event)
+	case strings.EqualFold(event.Type, "Rename"):
+		paths, err = pathsForRename(event)
+	default:
+		// events without file changes, such as Grab and Health
+		logger.Debug().Str("event", event.Type).Msg("Event Ignored")
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Msg("Required Fields Missing")
 		writer.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	unique := make(map[string]bool)
-	scans := make([]autoscan.Scan, 0)
+	var scans []autoscan.Scan
 
-	for _, f := range event.Files {
-		folderPath := path.Dir(h.rewrite(f.Path))
-		if _, ok := unique[folderPath]; ok {
-			continue
+	for folderPath, files := range paths {
+		if len(files) == 0 {
+			// scan the folder as a whole
+			files = []string{""}
 		}
 
-		// add scan
-		unique[folderPath] = true
-		scans = append(scans, autoscan.Scan{
-			Folder:   folderPath,
-			Priority: h.priority,
-			Time:     now().Unix(),
-		})
+		for _, filePath := range files {
+			scans = append(scans, autoscan.Scan{
+				Folder:       h.rewrite(folderPath),
+				RelativePath: filePath,
+				Priority:     h.priority,
+				Time:         now().Unix(),
+			})
+		}
 	}
 
-	err = h.callback(scans...)
-	if err != nil {
+	if err = h.callback(scans...); err != nil {
 		logger.Error().Err(err).Msg("Scan Enqueue Failed")
 		writer.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	writer.WriteHeader(http.StatusOK)
-	logger.Info().
-		Str("path", scans[0].Folder).
-		Str("event", event.Type).
-		Msg("Scan Enqueued")
+	for _, scan := range scans {
+		logger.Info().
+			Str("path", scan.Folder).
+			Str("event", event.Type).
+			Msg("Scan Enqueued")
+	}
+}
+
+// pathsForDownload returns the folder→files mapping of all imported book files.
+func pathsForDownload(event *readarrEvent) (map[string][]string, error) {
+	files := make([]string, 0, len(event.Files))
+	for _, f := range event.Files {
+		files = append(files, f.Path)
+	}
+
+	paths := arr.FilePaths(files...)
+	if len(paths) == 0 {
+		return nil, errors.New("required fields missing")
+	}
+
+	return paths, nil
+}
+
+// pathsForBookFile returns the folder→file mapping for BookFileDelete and Retag events.
+func pathsForBookFile(event *readarrEvent) (map[string][]string, error) {
+	if event.File.Path == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	return arr.FilePaths(event.File.Path), nil
+}
+
+// pathsForAuthor returns the author folder for BookDelete and AuthorDelete events.
+func pathsForAuthor(event *readarrEvent) (map[string][]string, error) {
+	if event.Author.Path == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	return map[string][]string{event.Author.Path: nil}, nil
+}
+
+// pathsForRename returns all affected folder→file mappings for a Rename event.
+// Both previous and current paths are included; duplicates are dropped.
+// Without renamed book files, the author folder is scanned as a whole.
+func pathsForRename(event *readarrEvent) (map[string][]string, error) {
+	files := make([]arr.RenamedFile, 0, len(event.RenamedFiles))
+	for _, renamedFile := range event.RenamedFiles {
+		files = append(files, arr.RenamedFile{
+			PreviousPath: renamedFile.PreviousPath,
+			Path:         renamedFile.Path,
+		})
+	}
+
+	paths := arr.RenamedPaths(files)
+
+	if len(paths) == 0 {
+		return pathsForAuthor(event)
+	}
+
+	return paths, nil
 }
 
 var now = time.Now
@@ -6,12 +6,34 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/cloudbox/autoscan"
 )
 
+func scansEqual(expected, actual []autoscan.Scan) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	// Sort both slices for comparison
+	sortScans := func(scans []autoscan.Scan) []autoscan.Scan {
+		sorted := make([]autoscan.Scan, len(scans))
+		copy(sorted, scans)
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].Folder != sorted[j].Folder {
+				return sorted[i].Folder < sorted[j].Folder
+			}
+			return sorted[i].RelativePath < sorted[j].RelativePath
+		})
+		return sorted
+	}
+
+	return reflect.DeepEqual(sortScans(expected), sortScans(actual))
+}
+
 func TestHandler(t *testing.T) {
 	type Given struct {
 		Config  Config
@@ -53,12 +75,131 @@ func TestHandler(t *testing.T) {
 			Expected{
 				StatusCode: 200,
 				Scans: []autoscan.Scan{{
-					Folder:   "/mnt/unionfs/Media/Books/Brandon Sanderson/The Way of Kings (2010)",
+					Folder:       "/mnt/unionfs/Media/Books/Brandon Sanderson/The Way of Kings (2010)",
+					RelativePath: "The Way of Kings - Brandon Sanderson.epub",
+					Priority:     5,
+					Time:         currentTime.Unix(),
+				}},
+			},
+		},
+		{
+			"Scans book folder on BookFileDelete event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/book_file_delete.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{{
+					Folder:       "/mnt/unionfs/Media/Books/Brandon Sanderson/The Way of Kings (2010)",
+					RelativePath: "The Way of Kings - Brandon Sanderson.epub",
+					Priority:     5,
+					Time:         currentTime.Unix(),
+				}},
+			},
+		},
+		{
+			"Scans book folder on Retag event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/retag.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{{
+					Folder:       "/mnt/unionfs/Media/Books/Brandon Sanderson/Oathbringer (2017)",
+					RelativePath: "Oathbringer - Brandon Sanderson.m4b",
+					Priority:     5,
+					Time:         currentTime.Unix(),
+				}},
+			},
+		},
+		{
+			"Scans author folder on BookDelete event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/book_delete.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{{
+					Folder:   "/mnt/unionfs/Media/Books/Brandon Sanderson",
 					Priority: 5,
 					Time:     currentTime.Unix(),
 				}},
 			},
 		},
+		{
+			"Scans author folder on AuthorDelete event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/author_delete.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{{
+					Folder:   "/mnt/unionfs/Media/Books/Joe Abercrombie",
+					Priority: 5,
+					Time:     currentTime.Unix(),
+				}},
+			},
+		},
+		{
+			"Scans previous and current folders on Rename event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/rename.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Books/Brandon Sanderson/Mistborn",
+						RelativePath: "Mistborn.epub",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Books/Brandon Sanderson/Mistborn",
+						RelativePath: "Mistborn.azw3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Books/Brandon Sanderson/Mistborn - The Final Empire (2006)",
+						RelativePath: "Mistborn - The Final Empire - Brandon Sanderson.epub",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Books/Brandon Sanderson/Mistborn - The Final Empire (2006)",
+						RelativePath: "Mistborn - The Final Empire - Brandon Sanderson.azw3",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Returns bad request on BookFileDelete event without book file",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/book_file_delete_invalid.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
+		{
+			"Returns 200 on unhandled event without emitting a scan",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/grab.json",
+			},
+			Expected{
+				StatusCode: 200,
+			},
+		},
 		{
 			"Returns bad request on invalid JSON",
 			Given{
@@ -84,7 +225,7 @@ func TestHandler(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.Name, func(t *testing.T) {
 			callback := func(scans ...autoscan.Scan) error {
-				if !reflect.DeepEqual(tc.Expected.Scans, scans) {
+				if !scansEqual(tc.Expected.Scans, scans) {
 					t.Logf("want: %v", tc.Expected.Scans)
 					t.Logf("got:  %v", scans)
 					t.Error("Scans do not equal")
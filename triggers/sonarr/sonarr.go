@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/hlog"
 
 	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/arr"
 )
 
 // Config holds configuration for the Sonarr trigger.
@@ -48,9 +50,20 @@ type handler struct {
 }
 
 type sonarrFile struct {
+	Path         string `json:"path"`
 	RelativePath string `json:"relativePath"`
 }
 
+// fullPath returns the path of the file within the series folder.
+// Falls back to the absolute path of the file when either path is missing.
+func (f sonarrFile) fullPath(seriesPath string) string {
+	if f.RelativePath == "" || seriesPath == "" {
+		return f.Path
+	}
+
+	return path.Join(seriesPath, f.RelativePath)
+}
+
 type sonarrSeries struct {
 	Path string `json:"path"`
 }
@@ -64,6 +77,8 @@ type sonarrRenamedFile struct {
 type sonarrEvent struct {
 	Type         string              `json:"eventType"`
 	File         sonarrFile          `json:"episodeFile"`
+	Files        []sonarrFile        `json:"episodeFiles"`
+	DeletedFiles []sonarrFile        `json:"deletedFiles"`
 	Series       sonarrSeries        `json:"series"`
 	RenamedFiles []sonarrRenamedFile `json:"renamedEpisodeFiles"`
 }
@@ -87,15 +102,15 @@ func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
 	}
 
 	var (
-		paths map[string]string
+		paths map[string][]string
 		err   error
 	)
 
 	switch {
 	case strings.EqualFold(event.Type, "Download") || strings.EqualFold(event.Type, "EpisodeFileDelete"):
 		paths, err = pathsForDownload(event)
-	case strings.EqualFold(event.Type, "SeriesDelete"):
-		paths, err = pathsForSeriesDelete(event)
+	case strings.EqualFold(event.Type, "SeriesAdd") || strings.EqualFold(event.Type, "SeriesDelete"):
+		paths, err = pathsForSeries(event)
 	case strings.EqualFold(event.Type, "Rename"):
 		paths, err = pathsForRename(event)
 	default:
@@ -110,13 +125,20 @@ func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
 
 	var scans []autoscan.Scan
 
-	for folderPath, filePath := range paths {
-		scans = append(scans, autoscan.Scan{
-			Folder:       h.rewrite(folderPath),
-			RelativePath: filePath,
-			Priority:     h.priority,
-			Time:         now().Unix(),
-		})
+	for folderPath, files := range paths {
+		if len(files) == 0 {
+			// scan the folder as a whole
+			files = []string{""}
+		}
+
+		for _, filePath := range files {
+			scans = append(scans, autoscan.Scan{
+				Folder:       h.rewrite(folderPath),
+				RelativePath: filePath,
+				Priority:     h.priority,
+				Time:         now().Unix(),
+			})
+		}
 	}
 
 	if err = h.callback(scans...); err != nil {
@@ -135,56 +157,51 @@ func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
 	writer.WriteHeader(http.StatusOK)
 }
 
-// pathsForDownload returns the folder→file mapping for Download and EpisodeFileDelete events.
-// A Download event covers both new files and upgrades.
-func pathsForDownload(event *sonarrEvent) (map[string]string, error) {
-	if event.File.RelativePath == "" || event.Series.Path == "" {
+// pathsForDownload returns the folder→files mapping for Download and EpisodeFileDelete events.
+// A Download event covers both new files and upgrades. Multi-file imports list
+// their files in episodeFiles, and upgrades list the replaced files in deletedFiles,
+// which may be located in another folder.
+func pathsForDownload(event *sonarrEvent) (map[string][]string, error) {
+	files := slices.Concat([]sonarrFile{event.File}, event.Files, event.DeletedFiles)
+
+	fullPaths := make([]string, 0, len(files))
+	for _, f := range files {
+		fullPaths = append(fullPaths, f.fullPath(event.Series.Path))
+	}
+
+	paths := arr.FilePaths(fullPaths...)
+	if len(paths) == 0 {
 		return nil, errors.New("required fields missing")
 	}
 
-	// Use path.Dir to get the directory in which the file is located.
-	// Use path.Base to get the filename.
-	full := path.Join(event.Series.Path, event.File.RelativePath)
-	return map[string]string{path.Dir(full): path.Base(full)}, nil
+	return paths, nil
 }
 
-// pathsForSeriesDelete returns the series root folder for a SeriesDelete event.
-func pathsForSeriesDelete(event *sonarrEvent) (map[string]string, error) {
+// pathsForSeries returns the series root folder for SeriesAdd and SeriesDelete events.
+func pathsForSeries(event *sonarrEvent) (map[string][]string, error) {
 	if event.Series.Path == "" {
 		return nil, errors.New("required fields missing")
 	}
 
-	return map[string]string{event.Series.Path: ""}, nil
+	return map[string][]string{event.Series.Path: nil}, nil
 }
 
 // pathsForRename returns all affected folder→file mappings for a Rename event.
 // Both previous and current paths are included; duplicates are dropped.
-func pathsForRename(event *sonarrEvent) (map[string]string, error) {
+func pathsForRename(event *sonarrEvent) (map[string][]string, error) {
 	if event.Series.Path == "" {
 		return nil, errors.New("required fields missing")
 	}
 
-	paths := make(map[string]string)
-	encountered := make(map[string]bool)
-
+	files := make([]arr.RenamedFile, 0, len(event.RenamedFiles))
 	for _, renamedFile := range event.RenamedFiles {
-		previousPath := path.Dir(renamedFile.PreviousPath)
-		previousFile := path.Base(renamedFile.PreviousPath)
-		currentPath := path.Dir(path.Join(event.Series.Path, renamedFile.RelativePath))
-		currentFile := path.Base(path.Join(event.Series.Path, renamedFile.RelativePath))
-
-		if _, ok := encountered[previousPath]; !ok {
-			encountered[previousPath] = true
-			paths[previousPath] = previousFile
-		}
-
-		if _, ok := encountered[currentPath]; !ok {
-			encountered[currentPath] = true
-			paths[currentPath] = currentFile
-		}
+		files = append(files, arr.RenamedFile{
+			PreviousPath: renamedFile.PreviousPath,
+			Path:         path.Join(event.Series.Path, renamedFile.RelativePath),
+		})
 	}
 
-	return paths, nil
+	return arr.RenamedPaths(files), nil
 }
 
 var now = time.Now
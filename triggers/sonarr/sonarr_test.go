@@ -124,12 +124,24 @@ func TestHandler(t *testing.T) {
 						Priority:     5,
 						Time:         currentTime.Unix(),
 					},
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Season 1",
+						RelativePath: "Westworld.S01E02.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
 					{
 						Folder:       "/mnt/unionfs/Media/TV/Westworld [imdb:tt0475784]/Season 1",
 						RelativePath: "Westworld.S01E01.mkv",
 						Priority:     5,
 						Time:         currentTime.Unix(),
 					},
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld [imdb:tt0475784]/Season 1",
+						RelativePath: "Westworld.S01E02.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
 					{
 						Folder:       "/mnt/unionfs/Media/TV/Westworld/Season 2",
 						RelativePath: "Westworld.S01E02.mkv",
@@ -162,6 +174,87 @@ func TestHandler(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Scans every file of a multi-file Download event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/multi_file.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Season 3",
+						RelativePath: "Westworld.S03E01.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Season 3",
+						RelativePath: "Westworld.S03E02.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Specials",
+						RelativePath: "Westworld.S00E01.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Scans the folders of deleted files on upgrade",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/upgrade.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Season 1",
+						RelativePath: "Westworld.S01E01.2160p.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Specials",
+						RelativePath: "Westworld.S01E01.720p.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Returns bad request on Download event without files",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/download_invalid.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
+		{
+			"Scans show folder on SeriesAdd event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/series_add.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:   "/mnt/unionfs/Media/TV/Severance",
+						Priority: 5,
+						Time:     currentTime.Unix(),
+					},
+				},
+			},
+		},
 		{
 			"Returns bad request on invalid JSON",
 			Given{
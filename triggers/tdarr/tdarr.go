@@ -0,0 +1,168 @@
+// Package tdarr provides an autoscan trigger for Tdarr transcode webhooks.
+package tdarr
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/hlog"
+
+	"github.com/cloudbox/autoscan"
+)
+
+// Config holds configuration for the Tdarr trigger.
+type Config struct {
+	Name      string             `yaml:"name"`
+	Priority  int                `yaml:"priority"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Verbosity string             `yaml:"verbosity"`
+}
+
+// New creates an autoscan-compatible HTTP Trigger for Tdarr webhooks.
+func New(c Config) (autoscan.HTTPTrigger, error) {
+	rewriter, err := autoscan.NewRewriter(c.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("create rewriter: %w", err)
+	}
+
+	trigger := func(callback autoscan.ProcessorFunc) http.Handler {
+		return handler{
+			callback: callback,
+			priority: c.Priority,
+			rewrite:  rewriter,
+		}
+	}
+
+	return trigger, nil
+}
+
+// successEvents are the event types of a transcoded file which replaced the original.
+var successEvents = []string{"TranscodeSuccess", "Transcode success"}
+
+type handler struct {
+	priority int
+	rewrite  autoscan.Rewriter
+	callback autoscan.ProcessorFunc
+}
+
+// tdarrFile is the path of a file within a Tdarr payload.
+// Tdarr sends either the path itself, or a file object from its database,
+// in which both _id and file hold the path.
+type tdarrFile string
+
+func (f *tdarrFile) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var obj struct {
+			ID   string `json:"_id"`
+			File string `json:"file"`
+		}
+
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("decode file object: %w", err)
+		}
+
+		*f = tdarrFile(cmp.Or(obj.File, obj.ID))
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("decode file: %w", err)
+	}
+
+	*f = tdarrFile(s)
+	return nil
+}
+
+type tdarrEvent struct {
+	Type string `json:"eventType"`
+
+	// File is the transcoded file.
+	File tdarrFile `json:"file"`
+	// OriginalFile is the file before transcoding, which differs
+	// from File when the transcode changed the container.
+	OriginalFile tdarrFile `json:"originalFile"`
+}
+
+func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
+	rlog := hlog.FromRequest(r)
+
+	event := new(tdarrEvent)
+	if err := json.NewDecoder(r.Body).Decode(event); err != nil {
+		rlog.Error().Err(err).Msg("Request Decode Failed")
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rlog.Trace().Interface("event", event).Msg("Webhook Payload")
+
+	if strings.EqualFold(event.Type, "Test") {
+		rlog.Info().Msg("Test Event")
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if !slices.ContainsFunc(successEvents, func(t string) bool { return strings.EqualFold(event.Type, t) }) {
+		// failed or skipped transcodes leave the file unchanged
+		rlog.Debug().Str("event", event.Type).Msg("Event Ignored")
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	paths, err := pathsForTranscode(event)
+	if err != nil {
+		rlog.Error().Err(err).Msg("Required Fields Missing")
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	scans := make([]autoscan.Scan, 0, len(paths))
+	for _, p := range paths {
+		scans = append(scans, autoscan.Scan{
+			Folder:       h.rewrite(path.Dir(p)),
+			RelativePath: path.Base(p),
+			Priority:     h.priority,
+			Time:         now().Unix(),
+		})
+	}
+
+	if err = h.callback(scans...); err != nil {
+		rlog.Error().Err(err).Msg("Scan Enqueue Failed")
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	for _, scan := range scans {
+		rlog.Info().
+			Str("path", scan.Folder).
+			Str("file", scan.RelativePath).
+			Msg("Scan Enqueued")
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// pathsForTranscode returns the transcoded file, and the original file when it was replaced
+// by a file with another name.
+func pathsForTranscode(event *tdarrEvent) ([]string, error) {
+	if event.File == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	paths := []string{string(event.File)}
+	if event.OriginalFile != "" && event.OriginalFile != event.File {
+		paths = append(paths, string(event.OriginalFile))
+	}
+
+	return paths, nil
+}
+
+var now = time.Now
@@ -0,0 +1,218 @@
+package tdarr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+)
+
+func scansEqual(expected, actual []autoscan.Scan) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	// Sort both slices for comparison
+	sortScans := func(scans []autoscan.Scan) []autoscan.Scan {
+		sorted := make([]autoscan.Scan, len(scans))
+		copy(sorted, scans)
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].Folder != sorted[j].Folder {
+				return sorted[i].Folder < sorted[j].Folder
+			}
+			return sorted[i].RelativePath < sorted[j].RelativePath
+		})
+		return sorted
+	}
+
+	return reflect.DeepEqual(sortScans(expected), sortScans(actual))
+}
+
+func TestHandler(t *testing.T) {
+	type Given struct {
+		Config  Config
+		Fixture string
+	}
+
+	type Expected struct {
+		Scans      []autoscan.Scan
+		StatusCode int
+	}
+
+	type Test struct {
+		Name     string
+		Given    Given
+		Expected Expected
+	}
+
+	standardConfig := Config{
+		Name:     "tdarr",
+		Priority: 5,
+		Rewrite: []autoscan.Rewrite{{
+			From: "^/(Movies|TV)/",
+			To:   "/mnt/unionfs/Media/$1/",
+		}},
+	}
+
+	currentTime := time.Now()
+	now = func() time.Time {
+		return currentTime
+	}
+
+	testCases := []Test{
+		{
+			"Scans the transcoded file",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/transcode.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Movies/Tenet (2020)",
+						RelativePath: "Tenet.2020.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Scans the original file when the container changed",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/container_change.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Movies/Tenet (2020)",
+						RelativePath: "Tenet.2020.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Movies/Tenet (2020)",
+						RelativePath: "Tenet.2020.avi",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Accepts the file objects of a flow",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/flow.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/TV/Westworld/Season 1",
+						RelativePath: "Westworld.S01E01.mkv",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Returns 200 on a failed transcode without emitting a scan",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/failure.json",
+			},
+			Expected{
+				StatusCode: 200,
+			},
+		},
+		{
+			"Returns 200 on an event without type without emitting a scan",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/no_event_type.json",
+			},
+			Expected{
+				StatusCode: 200,
+			},
+		},
+		{
+			"Returns bad request on missing file",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/missing.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
+		{
+			"Returns bad request on invalid JSON",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/invalid.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
+		{
+			"Returns 200 on Test event without emitting a scan",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/test.json",
+			},
+			Expected{
+				StatusCode: 200,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			callback := func(scans ...autoscan.Scan) error {
+				if !scansEqual(tc.Expected.Scans, scans) {
+					t.Log(scans)
+					t.Log(tc.Expected.Scans)
+					t.Error("Scans do not equal")
+					return errors.New("Scans do not equal")
+				}
+
+				return nil
+			}
+
+			trigger, err := New(tc.Given.Config)
+			if err != nil {
+				t.Fatalf("Could not create Tdarr Trigger: %v", err)
+			}
+
+			server := httptest.NewServer(trigger(callback))
+			defer server.Close()
+
+			request, err := os.Open(tc.Given.Fixture)
+			if err != nil {
+				t.Fatalf("Could not open the fixture: %s", tc.Given.Fixture)
+			}
+
+			res, err := http.Post(server.URL, "application/json", request)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			defer func() { _ = res.Body.Close() }()
+			if res.StatusCode != tc.Expected.StatusCode {
+				t.Errorf("Status codes do not match: %d vs %d", res.StatusCode, tc.Expected.StatusCode)
+			}
+		})
+	}
+}
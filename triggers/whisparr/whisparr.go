@@ -0,0 +1,199 @@
+// Package whisparr provides an autoscan trigger for Whisparr webhooks.
+package whisparr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/hlog"
+
+	"github.com/cloudbox/autoscan"
+	"github.com/cloudbox/autoscan/internal/arr"
+)
+
+// Config holds configuration for the Whisparr trigger.
+type Config struct {
+	Name      string             `yaml:"name"`
+	Priority  int                `yaml:"priority"`
+	Rewrite   []autoscan.Rewrite `yaml:"rewrite"`
+	Verbosity string             `yaml:"verbosity"`
+}
+
+// New creates an autoscan-compatible HTTP Trigger for Whisparr webhooks.
+func New(c Config) (autoscan.HTTPTrigger, error) {
+	rewriter, err := autoscan.NewRewriter(c.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("create rewriter: %w", err)
+	}
+
+	trigger := func(callback autoscan.ProcessorFunc) http.Handler {
+		return handler{
+			callback: callback,
+			priority: c.Priority,
+			rewrite:  rewriter,
+		}
+	}
+
+	return trigger, nil
+}
+
+type handler struct {
+	priority int
+	rewrite  autoscan.Rewriter
+	callback autoscan.ProcessorFunc
+}
+
+type whisparrFile struct {
+	RelativePath string `json:"relativePath"`
+}
+
+type whisparrMovie struct {
+	FolderPath string `json:"folderPath"`
+}
+
+type whisparrRenamedFile struct {
+	// use PreviousPath as the Movie.FolderPath might have changed.
+	PreviousPath string `json:"previousPath"`
+	RelativePath string `json:"relativePath"`
+}
+
+type whisparrEvent struct {
+	Type         string                `json:"eventType"`
+	File         whisparrFile          `json:"movieFile"`
+	Movie        whisparrMovie         `json:"movie"`
+	RenamedFiles []whisparrRenamedFile `json:"renamedMovieFiles"`
+}
+
+func (h handler) ServeHTTP(writer http.ResponseWriter, r *http.Request) {
+	rlog := hlog.FromRequest(r)
+
+	event := new(whisparrEvent)
+	if err := json.NewDecoder(r.Body).Decode(event); err != nil {
+		rlog.Error().Err(err).Msg("Request Decode Failed")
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rlog.Trace().Interface("event", event).Msg("Webhook Payload")
+
+	var (
+		paths map[string][]string
+		err   error
+	)
+
+	switch {
+	case isEvent(event.Type, "Test"):
+		rlog.Info().Msg("Test Event")
+		writer.WriteHeader(http.StatusOK)
+		return
+	case isEvent(event.Type, "Download", "MovieFileDelete", "SceneFileDelete"):
+		paths, err = pathsForDownload(event)
+	case isEvent(event.Type, "MovieDelete", "SceneDelete"):
+		paths, err = pathsForMovieDelete(event)
+	case isEvent(event.Type, "Rename"):
+		paths, err = pathsForRename(event)
+	default:
+		// events without file changes, such as Grab and Health
+		rlog.Debug().Str("event", event.Type).Msg("Event Ignored")
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err != nil {
+		rlog.Error().Err(err).Msg("Required Fields Missing")
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	scans := make([]autoscan.Scan, 0, len(paths))
+	for folderPath, files := range paths {
+		if len(files) == 0 {
+			// scan the folder as a whole
+			files = []string{""}
+		}
+
+		for _, filePath := range files {
+			scans = append(scans, autoscan.Scan{
+				Folder:       h.rewrite(folderPath),
+				RelativePath: filePath,
+				Priority:     h.priority,
+				Time:         now().Unix(),
+			})
+		}
+	}
+
+	if err = h.callback(scans...); err != nil {
+		rlog.Error().Err(err).Msg("Scan Enqueue Failed")
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	for _, scan := range scans {
+		rlog.Info().
+			Str("path", scan.Folder).
+			Str("event", event.Type).
+			Msg("Scan Enqueued")
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// pathsForDownload returns the folder→file mapping for Download and file delete events.
+func pathsForDownload(event *whisparrEvent) (map[string][]string, error) {
+	if event.File.RelativePath == "" || event.Movie.FolderPath == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	return arr.FilePaths(path.Join(event.Movie.FolderPath, event.File.RelativePath)), nil
+}
+
+// pathsForMovieDelete returns the scene folder for a MovieDelete or SceneDelete event.
+func pathsForMovieDelete(event *whisparrEvent) (map[string][]string, error) {
+	if event.Movie.FolderPath == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	return map[string][]string{event.Movie.FolderPath: nil}, nil
+}
+
+// pathsForRename returns all affected folder→file mappings for a Rename event.
+// Both previous and current paths are included; duplicates are dropped.
+// Without renamed files, the scene folder is scanned as a whole.
+func pathsForRename(event *whisparrEvent) (map[string][]string, error) {
+	if event.Movie.FolderPath == "" {
+		return nil, errors.New("required fields missing")
+	}
+
+	if len(event.RenamedFiles) == 0 {
+		return map[string][]string{event.Movie.FolderPath: nil}, nil
+	}
+
+	files := make([]arr.RenamedFile, 0, len(event.RenamedFiles))
+	for _, renamedFile := range event.RenamedFiles {
+		files = append(files, arr.RenamedFile{
+			PreviousPath: renamedFile.PreviousPath,
+			Path:         path.Join(event.Movie.FolderPath, renamedFile.RelativePath),
+		})
+	}
+
+	return arr.RenamedPaths(files), nil
+}
+
+// isEvent reports whether the event type equals one of the given types.
+// Whisparr names its events after either movies or scenes, depending on the version.
+func isEvent(eventType string, types ...string) bool {
+	for _, t := range types {
+		if strings.EqualFold(eventType, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var now = time.Now
@@ -0,0 +1,276 @@
+package whisparr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cloudbox/autoscan"
+)
+
+func scansEqual(expected, actual []autoscan.Scan) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	// Sort both slices for comparison
+	sortScans := func(scans []autoscan.Scan) []autoscan.Scan {
+		sorted := make([]autoscan.Scan, len(scans))
+		copy(sorted, scans)
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].Folder != sorted[j].Folder {
+				return sorted[i].Folder < sorted[j].Folder
+			}
+			return sorted[i].RelativePath < sorted[j].RelativePath
+		})
+		return sorted
+	}
+
+	return reflect.DeepEqual(sortScans(expected), sortScans(actual))
+}
+
+func TestHandler(t *testing.T) {
+	type Given struct {
+		Config  Config
+		Fixture string
+	}
+
+	type Expected struct {
+		Scans      []autoscan.Scan
+		StatusCode int
+	}
+
+	type Test struct {
+		Name     string
+		Given    Given
+		Expected Expected
+	}
+
+	standardConfig := Config{
+		Name:     "whisparr",
+		Priority: 5,
+		Rewrite: []autoscan.Rewrite{{
+			From: "/Scenes/*",
+			To:   "/mnt/unionfs/Media/Scenes/$1",
+		}},
+	}
+
+	currentTime := time.Now()
+	now = func() time.Time {
+		return currentTime
+	}
+
+	testCases := []Test{
+		{
+			"Download Event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/download.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Scenes/Vixen",
+						RelativePath: "Vixen.21.03.14.Scene.Title.1080p.mp4",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"MovieFileDelete Event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/movie_file_delete.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Scenes/Vixen",
+						RelativePath: "Vixen.21.03.14.Scene.Title.720p.mp4",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"MovieDelete Event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/movie_delete.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:   "/mnt/unionfs/Media/Scenes/Tushy",
+						Priority: 5,
+						Time:     currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"SceneDelete Event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/scene_delete.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:   "/mnt/unionfs/Media/Scenes/Blacked",
+						Priority: 5,
+						Time:     currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Rename Event",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/rename.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:   "/mnt/unionfs/Media/Scenes/Deeper",
+						Priority: 5,
+						Time:     currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Rename Event scans previous and current folders",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/rename_files.json",
+			},
+			Expected{
+				StatusCode: 200,
+				Scans: []autoscan.Scan{
+					{
+						Folder:       "/mnt/unionfs/Media/Scenes/Deeper",
+						RelativePath: "Deeper.21.05.06.Scene.Title.1080p.mp4",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Scenes/Deeper",
+						RelativePath: "Deeper.21.05.06.Scene.Title.720p.mp4",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Scenes/Deeper (2021)",
+						RelativePath: "Deeper - 2021-05-06 - Scene Title [WEBDL-1080p].mp4",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+					{
+						Folder:       "/mnt/unionfs/Media/Scenes/Deeper (2021)",
+						RelativePath: "Deeper - 2021-05-06 - Scene Title [WEBDL-720p].mp4",
+						Priority:     5,
+						Time:         currentTime.Unix(),
+					},
+				},
+			},
+		},
+		{
+			"Returns 200 on unhandled event without emitting a scan",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/grab.json",
+			},
+			Expected{
+				StatusCode: 200,
+			},
+		},
+		{
+			"Returns bad request on missing fields",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/missing_fields.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
+		{
+			"Returns bad request on invalid JSON",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/invalid.json",
+			},
+			Expected{
+				StatusCode: 400,
+			},
+		},
+		{
+			"Returns 200 on Test event without emitting a scan",
+			Given{
+				Config:  standardConfig,
+				Fixture: "testdata/test.json",
+			},
+			Expected{
+				StatusCode: 200,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			callback := func(scans ...autoscan.Scan) error {
+				if len(tc.Expected.Scans) == 0 {
+					t.Error("Unexpected callback")
+					return errors.New("unexpected callback")
+				}
+
+				if !scansEqual(tc.Expected.Scans, scans) {
+					t.Log(scans)
+					t.Log(tc.Expected.Scans)
+					t.Error("Scans do not equal")
+					return errors.New("Scans do not equal")
+				}
+
+				return nil
+			}
+
+			trigger, err := New(tc.Given.Config)
+			if err != nil {
+				t.Fatalf("Could not create Whisparr Trigger: %v", err)
+			}
+
+			server := httptest.NewServer(trigger(callback))
+			defer server.Close()
+
+			request, err := os.Open(tc.Given.Fixture)
+			if err != nil {
+				t.Fatalf("Could not open the fixture: %s", tc.Given.Fixture)
+			}
+
+			res, err := http.Post(server.URL, "application/json", request)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			defer func() { _ = res.Body.Close() }()
+			if res.StatusCode != tc.Expected.StatusCode {
+				t.Errorf("Status codes do not match: %d vs %d", res.StatusCode, tc.Expected.StatusCode)
+			}
+		})
+	}
+}